@@ -0,0 +1,135 @@
+package convert
+
+import (
+	"math"
+
+	"github.com/nickwells/mathutil.mod/v2/mathutil"
+	"github.com/nickwells/units.mod/v2/units"
+)
+
+// Request describes the conversion of a value from one unit into one or
+// more target units. If more than one target unit is given the value is
+// converted into a compound value (see Compound).
+//
+// The Family is optional; if it is not given the first family having all
+// the units will be used.
+type Request struct {
+	Family string
+	From   string
+	To     []string
+	Value  float64
+
+	// RoughPrecision, if greater than zero, causes each converted value to
+	// be rounded to the nearest multiple of 10 or 5 within this
+	// percentage of the value
+	RoughPrecision float64
+
+	// Delta, if set, causes the value to be converted as a difference
+	// between two values (see ConvertDelta)
+	Delta bool
+}
+
+// Result holds the result of a conversion
+type Result struct {
+	From units.ValUnit
+	To   []units.ValUnit
+}
+
+// Convert performs the conversion described by the Request
+func Convert(req Request) (Result, error) {
+	var (
+		f   *units.Family
+		err error
+	)
+
+	if req.Family != "" {
+		f, err = units.GetFamily(req.Family)
+		if err != nil {
+			return Result{}, NewError(CatUnknownUnit, "%s", err)
+		}
+	}
+
+	fromUnit, toUnits, err := FindUnits(f, req.From, req.To)
+	if err != nil {
+		return Result{}, err
+	}
+
+	rval := Result{From: units.ValUnit{V: req.Value, U: fromUnit}}
+
+	if req.Delta {
+		rval.To, err = CompoundDelta(rval.From, toUnits, req.RoughPrecision)
+	} else {
+		rval.To, err = Compound(rval.From, toUnits, req.RoughPrecision)
+	}
+
+	return rval, err
+}
+
+// convertFunc converts a value into the given unit
+type convertFunc func(units.ValUnit, units.Unit) (units.ValUnit, error)
+
+// Compound converts the value into the target units. Every converted value
+// except the last is reduced to its whole number part and the remainder is
+// carried into the following units. So, for instance, 1.8 metres converted
+// into feet and inches will give 5 feet and 10.866 inches. If roughPrecision
+// is greater than zero each converted value is first rounded to the nearest
+// multiple of 10 or 5 within that percentage of the value.
+func Compound(
+	v units.ValUnit, to []units.Unit, roughPrecision float64,
+) ([]units.ValUnit, error) {
+	return compound(v, to, roughPrecision, units.ValUnit.Convert)
+}
+
+// CompoundDelta is as for Compound except that the value is converted as a
+// difference between two values (see ConvertDelta).
+func CompoundDelta(
+	v units.ValUnit, to []units.Unit, roughPrecision float64,
+) ([]units.ValUnit, error) {
+	return compound(v, to, roughPrecision, ConvertDelta)
+}
+
+// compound converts the value into the target units using the conversion
+// function, as described for Compound.
+func compound(
+	v units.ValUnit, to []units.Unit, roughPrecision float64,
+	conv convertFunc,
+) ([]units.ValUnit, error) {
+	rval := make([]units.ValUnit, 0, len(to))
+
+	for i, unitTo := range to {
+		converted, err := conv(v, unitTo)
+		if err != nil {
+			return rval, Error{
+				Category: CatBadConversion,
+				Unit:     unitTo.ID(),
+				Msg:      err.Error(),
+			}
+		}
+
+		if roughPrecision > 0 {
+			converted.V = mathutil.Roughly(converted.V, roughPrecision)
+		}
+
+		if i != len(to)-1 {
+			intPart := math.Floor(converted.V)
+			fracPart := converted.V - intPart
+			converted.V = intPart
+			backVal := units.ValUnit{V: fracPart, U: unitTo}
+
+			convertedBack, err := conv(backVal, v.U)
+			if err != nil {
+				return rval, Error{
+					Category: CatBadConversion,
+					Unit:     v.U.ID(),
+					Msg:      err.Error(),
+				}
+			}
+
+			v.V = convertedBack.V
+		}
+
+		rval = append(rval, converted)
+	}
+
+	return rval, nil
+}
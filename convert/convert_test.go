@@ -0,0 +1,89 @@
+package convert
+
+import (
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestConvert(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		req     Request
+		expVals []float64
+		expCat  Category
+	}{
+		{
+			ID: testhelper.MkID("simple"),
+			req: Request{
+				From: "chain", To: []string{"metre"}, Value: 80,
+			},
+			expVals: []float64{1609.344},
+		},
+		{
+			ID: testhelper.MkID("temperature"),
+			req: Request{
+				From: "F", To: []string{"C"}, Value: 212,
+			},
+			expVals: []float64{100},
+		},
+		{
+			ID: testhelper.MkID("compound"),
+			req: Request{
+				Family: "length",
+				From:   "inch", To: []string{"foot", "inch"}, Value: 70,
+			},
+			expVals: []float64{5, 10},
+		},
+		{
+			ID: testhelper.MkID("roughly"),
+			req: Request{
+				From: "chain", To: []string{"metre"}, Value: 80,
+				RoughPrecision: 1,
+			},
+			expVals: []float64{1600},
+		},
+		{
+			ID: testhelper.MkID("unknown unit"),
+			ExpErr: testhelper.MkExpErr(
+				`there is no unit-family with a unit called "chian"`),
+			req: Request{
+				From: "chian", To: []string{"metre"}, Value: 1,
+			},
+			expCat: CatUnknownUnit,
+		},
+		{
+			ID: testhelper.MkID("family mismatch"),
+			ExpErr: testhelper.MkExpErr(
+				`there is no unit-family having both "chain" and "kg"`),
+			req: Request{
+				From: "chain", To: []string{"kg"}, Value: 1,
+			},
+			expCat: CatFamilyMismatch,
+		},
+	}
+
+	for _, tc := range testCases {
+		res, err := Convert(tc.req)
+		if testhelper.CheckExpErr(t, err, tc) && err == nil {
+			if len(res.To) != len(tc.expVals) {
+				t.Log(tc.IDStr())
+				t.Errorf("\t: expected %d values, got %d",
+					len(tc.expVals), len(res.To))
+
+				continue
+			}
+
+			for i, v := range res.To {
+				testhelper.DiffFloat(t, tc.IDStr(), "converted value",
+					v.V, tc.expVals[i], 1e-9)
+			}
+		}
+
+		if err != nil {
+			testhelper.DiffString(t, tc.IDStr(), "error category",
+				string(AsError(err).Category), string(tc.expCat))
+		}
+	}
+}
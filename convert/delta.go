@@ -0,0 +1,52 @@
+package convert
+
+import (
+	"github.com/nickwells/units.mod/v2/units"
+)
+
+// HasOffset returns true if converting a value in the unit to or from the
+// base unit of its family involves adding an offset as well as multiplying
+// by a factor. Temperature scales, such as Fahrenheit, are like this.
+func HasOffset(u units.Unit) bool {
+	return u.ConvPreAdd() != 0 || u.ConvPostAdd() != 0
+}
+
+// ConvertDelta converts the value, taken as the difference between two
+// values, into the given unit. Only the conversion factors are applied;
+// any offsets are ignored. So, for instance, a difference of 10 degrees
+// Celsius is a difference of 18 degrees Fahrenheit. For units without
+// offsets this gives the same result as ValUnit.Convert.
+func ConvertDelta(v units.ValUnit, to units.Unit) (units.ValUnit, error) {
+	rval := units.ValUnit{U: to}
+
+	if v.U.Family() != to.Family() {
+		return rval,
+			NewError(CatBadConversion,
+				"mismatched unit families. Cannot convert units from %s to %s",
+				v.U.Family().Name(), to.Family().Name())
+	}
+
+	if v.U.ConvFactor() == 0 || to.ConvFactor() == 0 {
+		return rval,
+			NewError(CatBadConversion, "bad units - a zero conversion factor")
+	}
+
+	rval.V = v.V * v.U.ConvFactor() / to.ConvFactor()
+
+	return rval, nil
+}
+
+// MayBeDelta returns true if a conversion from the unit into any of the
+// target units involves an offset. A value in such a unit may be a
+// difference, rather than an absolute value, in which case it should be
+// converted with ConvertDelta. The size of the value gives no reliable
+// clue as to which was meant and so it is not considered.
+func MayBeDelta(from units.Unit, to []units.Unit) bool {
+	for _, u := range to {
+		if !units.Equals(u, from) && (HasOffset(u) || HasOffset(from)) {
+			return true
+		}
+	}
+
+	return false
+}
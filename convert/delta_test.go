@@ -0,0 +1,110 @@
+package convert
+
+import (
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+	"github.com/nickwells/units.mod/v2/units"
+)
+
+func TestConvertDelta(t *testing.T) {
+	temp := units.GetFamilyOrPanic(units.Temperature)
+	dist := units.GetFamilyOrPanic(units.Distance)
+
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		v      units.ValUnit
+		to     units.Unit
+		expVal float64
+	}{
+		{
+			ID:     testhelper.MkID("C to F"),
+			v:      units.ValUnit{V: 10, U: temp.GetUnitOrPanic("C")},
+			to:     temp.GetUnitOrPanic("F"),
+			expVal: 18,
+		},
+		{
+			ID:     testhelper.MkID("F to K"),
+			v:      units.ValUnit{V: 9, U: temp.GetUnitOrPanic("F")},
+			to:     temp.GetUnitOrPanic("K"),
+			expVal: 5,
+		},
+		{
+			ID:     testhelper.MkID("negative factor"),
+			v:      units.ValUnit{V: 10, U: temp.GetUnitOrPanic("C")},
+			to:     temp.GetUnitOrPanic("D"),
+			expVal: -15,
+		},
+		{
+			ID:     testhelper.MkID("no offset"),
+			v:      units.ValUnit{V: 1, U: dist.GetUnitOrPanic("foot")},
+			to:     dist.GetUnitOrPanic("inch"),
+			expVal: 12,
+		},
+		{
+			ID:     testhelper.MkID("mismatched families"),
+			ExpErr: testhelper.MkExpErr("mismatched unit families"),
+			v:      units.ValUnit{V: 1, U: dist.GetUnitOrPanic("foot")},
+			to:     temp.GetUnitOrPanic("C"),
+		},
+	}
+
+	for _, tc := range testCases {
+		converted, err := ConvertDelta(tc.v, tc.to)
+		if testhelper.CheckExpErr(t, err, tc) && err == nil {
+			testhelper.DiffFloat(t, tc.IDStr(), "converted value",
+				converted.V, tc.expVal, 1e-9)
+		}
+	}
+}
+
+func TestMayBeDelta(t *testing.T) {
+	temp := units.GetFamilyOrPanic(units.Temperature)
+	dist := units.GetFamilyOrPanic(units.Distance)
+	c := temp.GetUnitOrPanic("C")
+	f := temp.GetUnitOrPanic("F")
+	k := temp.GetUnitOrPanic("K")
+
+	testCases := []struct {
+		testhelper.ID
+		from   units.Unit
+		to     []units.Unit
+		expVal bool
+	}{
+		{
+			ID:     testhelper.MkID("temperature"),
+			from:   c,
+			to:     []units.Unit{f},
+			expVal: true,
+		},
+		{
+			ID:     testhelper.MkID("offset target only"),
+			from:   k,
+			to:     []units.Unit{c},
+			expVal: true,
+		},
+		{
+			ID:     testhelper.MkID("one of several targets"),
+			from:   k,
+			to:     []units.Unit{k, f},
+			expVal: true,
+		},
+		{
+			ID:     testhelper.MkID("same unit"),
+			from:   f,
+			to:     []units.Unit{f},
+			expVal: false,
+		},
+		{
+			ID:   testhelper.MkID("no offset"),
+			from: dist.GetUnitOrPanic("foot"),
+			to:   []units.Unit{dist.GetUnitOrPanic("inch")},
+		},
+	}
+
+	for _, tc := range testCases {
+		testhelper.DiffBool(t, tc.IDStr(), "may be delta",
+			MayBeDelta(tc.from, tc.to), tc.expVal)
+	}
+}
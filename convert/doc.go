@@ -0,0 +1,12 @@
+/*
+Package convert contains the conversion logic used by the unitconv
+program. It can be used to find units by name, to convert a value between
+units (including splitting a value into a compound value such as feet and
+inches), to find the units in which a value is a small, whole number and to
+choose the most natural unit for a value in a given system of measurement.
+
+Errors returned by the functions in this package are of type Error and carry
+a Category so that callers can react to them without having to parse the
+message.
+*/
+package convert
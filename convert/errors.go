@@ -0,0 +1,57 @@
+package convert
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Category classifies the errors returned by this package
+type Category string
+
+// These are the categories of error that can be returned
+const (
+	CatBadConversion  Category = "bad-conversion"
+	CatUnknownUnit    Category = "unknown-unit"
+	CatAmbiguousUnit  Category = "ambiguous-unit"
+	CatFamilyMismatch Category = "family-mismatch"
+	CatBadValue       Category = "bad-value"
+	CatPrecisionLoss  Category = "precision-loss"
+	CatPartialFailure Category = "partial-failure"
+)
+
+// Error is an error with an associated Category. It may also record the
+// unit name and the families of units associated with the error and, for
+// an unknown unit, suggested alternative unit names.
+type Error struct {
+	Category    Category
+	Unit        string
+	Families    []string
+	Suggestions []string
+	Msg         string
+}
+
+// Error returns the error message
+func (e Error) Error() string {
+	return e.Msg
+}
+
+// NewError returns an Error with the given category and a message
+// constructed from the format and args.
+func NewError(cat Category, format string, args ...any) Error {
+	return Error{
+		Category: cat,
+		Msg:      fmt.Sprintf(format, args...),
+	}
+}
+
+// AsError converts the error into an Error. An error which is not an Error
+// is given the CatBadConversion category.
+func AsError(err error) Error {
+	ce := Error{
+		Category: CatBadConversion,
+		Msg:      err.Error(),
+	}
+	_ = errors.As(err, &ce)
+
+	return ce
+}
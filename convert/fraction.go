@@ -0,0 +1,135 @@
+package convert
+
+import (
+	"math"
+	"strconv"
+)
+
+// MaxAutoDenominator is the largest denominator that AutoFraction will use
+const MaxAutoDenominator = 64
+
+// maxFractionVal is the largest value (after multiplying by the
+// denominator) that can be shown as a fraction. This is well within the
+// range of an int64 and values of this size are exact in a float64.
+const maxFractionVal = 1 << 52
+
+// Fraction represents a value as a mixed fraction, a whole number and a
+// proper fraction in its lowest terms, together with the difference between
+// the value and the fraction.
+type Fraction struct {
+	Negative    bool
+	Whole       int64
+	Numerator   int64
+	Denominator int64
+	// Residual is the value less the value of the fraction
+	Residual float64
+}
+
+// Value returns the value of the fraction
+func (f Fraction) Value() float64 {
+	v := float64(f.Whole)
+	if f.Numerator != 0 {
+		v += float64(f.Numerator) / float64(f.Denominator)
+	}
+
+	if f.Negative {
+		return -v
+	}
+
+	return v
+}
+
+// String returns the fraction as a mixed fraction such as "3 5/16". The
+// whole number is omitted if it is zero and the fraction is omitted if
+// the numerator is zero. The residual is not shown.
+func (f Fraction) String() string {
+	s := ""
+	if f.Negative {
+		s = "-"
+	}
+
+	if f.Numerator == 0 {
+		return s + strconv.FormatInt(f.Whole, 10)
+	}
+
+	if f.Whole != 0 {
+		s += strconv.FormatInt(f.Whole, 10) + " "
+	}
+
+	return s + strconv.FormatInt(f.Numerator, 10) +
+		"/" + strconv.FormatInt(f.Denominator, 10)
+}
+
+// fractionDiff returns the absolute difference between the value
+// multiplied by m and the nearest whole number. This is the distance from
+// the value to the nearest multiple of 1/m, measured in units of 1/m.
+func fractionDiff(v, m float64) float64 {
+	vm := v * m
+
+	return math.Abs(vm - math.Round(vm))
+}
+
+// gcd returns the greatest common divisor of a and b
+func gcd(a, b int64) int64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+
+	return a
+}
+
+// NearestFraction returns the multiple of 1/denom nearest to the value
+// as a mixed fraction in its lowest terms. It returns a CatBadValue Error
+// if the denominator is less than one or if the value is too large to be
+// shown as a fraction.
+func NearestFraction(v float64, denom int64) (Fraction, error) {
+	if denom < 1 {
+		return Fraction{},
+			NewError(CatBadValue,
+				"the denominator of a fraction (%d) must be greater than zero",
+				denom)
+	}
+
+	absV := math.Abs(v)
+	if absV*float64(denom) >= maxFractionVal {
+		return Fraction{},
+			NewError(CatBadValue,
+				"the value (%g) is too large to show as a fraction", v)
+	}
+
+	n := int64(math.Round(absV * float64(denom)))
+
+	f := Fraction{
+		Negative:    v < 0 && n != 0,
+		Whole:       n / denom,
+		Numerator:   n % denom,
+		Denominator: denom,
+	}
+
+	if f.Numerator != 0 {
+		g := gcd(f.Numerator, f.Denominator)
+		f.Numerator /= g
+		f.Denominator /= g
+	}
+
+	f.Residual = v - f.Value()
+
+	return f, nil
+}
+
+// AutoFraction returns the value as a mixed fraction using the smallest
+// power-of-two denominator, from 2 up to MaxAutoDenominator, for which the
+// value is within the tolerance of a multiple of 1/denominator. If there
+// is no such denominator then the nearest multiple of 1/MaxAutoDenominator
+// is used. Errors are as for NearestFraction.
+func AutoFraction(v, tolerance float64) (Fraction, error) {
+	denom := int64(2) //nolint:mnd
+
+	for ; denom < MaxAutoDenominator; denom *= 2 {
+		if fractionDiff(v, float64(denom))/float64(denom) <= tolerance {
+			break
+		}
+	}
+
+	return NearestFraction(v, denom)
+}
@@ -0,0 +1,107 @@
+package convert
+
+import (
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestNearestFraction(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		v           float64
+		denom       int64
+		expStr      string
+		expResidual float64
+	}{
+		{
+			ID: testhelper.MkID("exact"),
+			v:  3.3125, denom: 16, expStr: "3 5/16",
+		},
+		{
+			ID: testhelper.MkID("reduced"),
+			v:  2.5, denom: 16, expStr: "2 1/2",
+		},
+		{
+			ID: testhelper.MkID("whole"),
+			v:  2.99, denom: 8, expStr: "3", expResidual: -0.01,
+		},
+		{
+			ID: testhelper.MkID("proper fraction"),
+			v:  0.26, denom: 4, expStr: "1/4", expResidual: 0.01,
+		},
+		{
+			ID: testhelper.MkID("negative"),
+			v:  -1.75, denom: 4, expStr: "-1 3/4",
+		},
+		{
+			ID: testhelper.MkID("negative rounds to zero"),
+			v:  -0.01, denom: 4, expStr: "0", expResidual: -0.01,
+		},
+		{
+			ID: testhelper.MkID("thirds"),
+			v:  1.0 / 3.0, denom: 3, expStr: "1/3",
+		},
+		{
+			ID:     testhelper.MkID("bad denominator"),
+			ExpErr: testhelper.MkExpErr("must be greater than zero"),
+			v:      1, denom: 0,
+		},
+		{
+			ID:     testhelper.MkID("too large"),
+			ExpErr: testhelper.MkExpErr("too large to show as a fraction"),
+			v:      1e20, denom: 64,
+		},
+	}
+
+	for _, tc := range testCases {
+		f, err := NearestFraction(tc.v, tc.denom)
+		if testhelper.CheckExpErr(t, err, tc) && err == nil {
+			testhelper.DiffString(t, tc.IDStr(), "fraction",
+				f.String(), tc.expStr)
+			testhelper.DiffFloat(t, tc.IDStr(), "residual",
+				f.Residual, tc.expResidual, 1e-9)
+		}
+	}
+}
+
+func TestAutoFraction(t *testing.T) {
+	const tolerance = 0.0005
+
+	testCases := []struct {
+		testhelper.ID
+		v        float64
+		expStr   string
+		expDenom int64
+	}{
+		{ID: testhelper.MkID("half"), v: 0.5, expStr: "1/2", expDenom: 2},
+		{
+			ID: testhelper.MkID("sixteenths"),
+			v:  3.3125, expStr: "3 5/16", expDenom: 16,
+		},
+		{
+			ID: testhelper.MkID("within tolerance"),
+			v:  3.1252, expStr: "3 1/8", expDenom: 8,
+		},
+		{
+			ID: testhelper.MkID("not a power of two"),
+			v:  0.3, expStr: "19/64", expDenom: 64,
+		},
+		{ID: testhelper.MkID("whole"), v: 7, expStr: "7", expDenom: 2},
+	}
+
+	for _, tc := range testCases {
+		f, err := AutoFraction(tc.v, tolerance)
+		if err != nil {
+			t.Log(tc.IDStr())
+			t.Errorf("\t: unexpected error: %s", err)
+
+			continue
+		}
+
+		testhelper.DiffString(t, tc.IDStr(), "fraction", f.String(), tc.expStr)
+		testhelper.DiffInt(t, tc.IDStr(), "denominator",
+			f.Denominator, tc.expDenom)
+	}
+}
@@ -0,0 +1,201 @@
+package convert
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/nickwells/units.mod/v2/units"
+)
+
+// unitMap maps names to the matching units, at most one per family, sorted
+// by family name.
+type unitMap map[string][]units.Unit
+
+// unitIndex holds the maps used to find units by name
+type unitIndex struct {
+	// exact maps every name by which a unit can be found to the units
+	exact unitMap
+	// folded maps the lower-case form of the names to the units. Names
+	// whose lower-case forms are shared by more than one unit in a family
+	// are not given for that family.
+	folded unitMap
+}
+
+// spellingVariants maps alternative spellings of parts of unit names to the
+// spellings used by the units package
+var spellingVariants = strings.NewReplacer(
+	"meter", "metre",
+	"Meter", "Metre",
+	"METER", "METRE",
+	"liter", "litre",
+	"Liter", "Litre",
+	"LITER", "LITRE",
+)
+
+// getIndex returns the unit index, building it on first use. The index is
+// only built once and is safe for concurrent use thereafter.
+var getIndex = sync.OnceValue(buildIndex)
+
+// buildIndex builds the index of unit names. Each unit can be found by its
+// ID and by any of its aliases, exactly as the units package would find
+// it. It can also be found by its name, plural name and abbreviation so
+// long as these are not already the ID or alias of any unit and do not
+// clash with the name of some other unit in the same family.
+func buildIndex() unitIndex {
+	idx := unitIndex{exact: unitMap{}}
+	families := sortedFamilies()
+
+	for _, f := range families {
+		// a name can be both an ID and an alias in the same family; the
+		// units package will find the unit with that ID.
+		names := slices.Concat(f.GetUnitNames(), f.GetUnitAliases())
+		slices.Sort(names)
+
+		for _, name := range slices.Compact(names) {
+			idx.exact[name] = append(idx.exact[name], f.GetUnitOrPanic(name))
+		}
+	}
+
+	descIdx := unitMap{}
+
+	for _, f := range families {
+		for name, u := range descriptiveNames(f, idx.exact) {
+			descIdx[name] = append(descIdx[name], u)
+		}
+	}
+
+	for name, us := range descIdx {
+		idx.exact[name] = us
+	}
+
+	idx.folded = foldNames(idx.exact)
+
+	for _, um := range []unitMap{idx.exact, idx.folded} {
+		for _, us := range um {
+			slices.SortFunc(us, func(a, b units.Unit) int {
+				return cmp.Compare(a.Family().Name(), b.Family().Name())
+			})
+		}
+	}
+
+	return idx
+}
+
+// descriptiveNames returns the names, plural names and abbreviations of
+// the units in the family which can be used to find them unambiguously. A
+// name that is already in the index or that is shared by more than one unit
+// in the family is not returned.
+func descriptiveNames(f *units.Family, exact unitMap) map[string]units.Unit {
+	names := map[string]units.Unit{}
+	clashes := map[string]bool{}
+
+	for _, u := range f.GetUnits() {
+		for _, name := range []string{u.Name(), u.NamePlural(), u.Abbrev()} {
+			if name == "" {
+				continue
+			}
+
+			if _, ok := exact[name]; ok {
+				continue
+			}
+
+			if other, ok := names[name]; ok && other.ID() != u.ID() {
+				clashes[name] = true
+			}
+
+			names[name] = u
+		}
+	}
+
+	for name := range clashes {
+		delete(names, name)
+	}
+
+	return names
+}
+
+// foldNames returns a map from the lower-case form of the names to the
+// units. If, in some family, the lower-case form is shared by names of
+// different units then no unit from that family is given for that name.
+func foldNames(exact unitMap) unitMap {
+	type famName struct {
+		family *units.Family
+		name   string
+	}
+
+	found := map[famName]units.Unit{}
+	clashes := map[famName]bool{}
+
+	for name, us := range exact {
+		lc := strings.ToLower(name)
+
+		for _, u := range us {
+			key := famName{family: u.Family(), name: lc}
+			if other, ok := found[key]; ok && other.ID() != u.ID() {
+				clashes[key] = true
+			}
+
+			found[key] = u
+		}
+	}
+
+	folded := unitMap{}
+
+	for key, u := range found {
+		if clashes[key] {
+			continue
+		}
+
+		folded[key.name] = append(folded[key.name], u)
+	}
+
+	return folded
+}
+
+// candidates returns the matching units for each of the ways of
+// interpreting the name, in the order that they should be tried: the name
+// as given; with its spelling normalised; ignoring case; and ignoring case
+// with its spelling normalised.
+func (idx unitIndex) candidates(uName string) [][]units.Unit {
+	respelt := spellingVariants.Replace(uName)
+
+	return [][]units.Unit{
+		idx.exact[uName],
+		idx.exact[respelt],
+		idx.folded[strings.ToLower(uName)],
+		idx.folded[strings.ToLower(respelt)],
+	}
+}
+
+// lookup returns the units with the given name, at most one per family,
+// sorted by family name. If there are no units with exactly that name then
+// the name is looked up with its spelling normalised and then ignoring
+// case.
+func (idx unitIndex) lookup(uName string) []units.Unit {
+	for _, us := range idx.candidates(uName) {
+		if len(us) > 0 {
+			return us
+		}
+	}
+
+	return nil
+}
+
+// lookupInFamily returns the unit with the given name in the family and
+// true, or false if there is no such unit. The name is looked up in the
+// same way as for lookup.
+func (idx unitIndex) lookupInFamily(
+	f *units.Family, uName string,
+) (units.Unit, bool) {
+	for _, us := range idx.candidates(uName) {
+		for _, u := range us {
+			if u.Family() == f {
+				return u, true
+			}
+		}
+	}
+
+	return units.Unit{}, false
+}
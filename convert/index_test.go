@@ -0,0 +1,170 @@
+package convert
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+	"github.com/nickwells/units.mod/v2/units"
+)
+
+// scanFamiliesWithUnit finds the families having the named unit by asking
+// every family in turn. This is how units were found before the index was
+// introduced and it is used to check the index and to measure the gain.
+func scanFamiliesWithUnit(uName string) []string {
+	fNames := []string{}
+
+	for _, f := range units.GetFamilies() {
+		if _, err := f.GetUnit(uName); err == nil {
+			fNames = append(fNames, f.Name())
+		}
+	}
+
+	slices.Sort(fNames)
+
+	return fNames
+}
+
+// allUnitIDsAndAliases returns every name by which the units package can
+// find a unit
+func allUnitIDsAndAliases() []string {
+	names := []string{}
+
+	for _, f := range sortedFamilies() {
+		ids := f.GetUnitNames()
+		slices.Sort(ids)
+		names = append(names, ids...)
+
+		aliases := f.GetUnitAliases()
+		slices.Sort(aliases)
+		names = append(names, aliases...)
+	}
+
+	return slices.Compact(names)
+}
+
+func TestIndexMatchesScan(t *testing.T) {
+	for _, name := range allUnitIDsAndAliases() {
+		id := testhelper.MkID(fmt.Sprintf("unit name: %q", name))
+
+		testhelper.DiffStringSlice(t, id.IDStr(), "families",
+			FamiliesWithUnit(name), scanFamiliesWithUnit(name))
+
+		for _, fName := range scanFamiliesWithUnit(name) {
+			f := units.GetFamilyOrPanic(fName)
+			expU := f.GetUnitOrPanic(name)
+
+			u, err := FindUnit(f, name)
+			if err != nil {
+				t.Log(id.IDStr())
+				t.Errorf("\t: unexpected error: %v", err)
+
+				continue
+			}
+
+			testhelper.DiffString(t, id.IDStr(), "unit ID", u.ID(), expU.ID())
+		}
+	}
+}
+
+func TestIndexDescriptiveNames(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		fName string
+		uName string
+		expID string
+	}{
+		{
+			ID:    testhelper.MkID("plural name"),
+			fName: units.Energy, uName: "kilojoules", expID: "kJ",
+		},
+		{
+			ID:    testhelper.MkID("name"),
+			fName: units.Energy, uName: "kilojoule", expID: "kJ",
+		},
+		{
+			ID:     testhelper.MkID("unknown"),
+			ExpErr: testhelper.MkExpErr(`called "nonesuch"`),
+			fName:  units.Energy, uName: "nonesuch",
+		},
+	}
+
+	for _, tc := range testCases {
+		u, err := FindUnit(units.GetFamilyOrPanic(tc.fName), tc.uName)
+		if testhelper.CheckExpErr(t, err, tc) && err == nil {
+			testhelper.DiffString(t, tc.IDStr(), "unit ID", u.ID(), tc.expID)
+		}
+	}
+}
+
+func BenchmarkFamiliesWithUnit(b *testing.B) {
+	names := allUnitIDsAndAliases()
+
+	_ = getIndex() // build the index outside the timed loop
+
+	b.Run("indexed", func(b *testing.B) {
+		for i := 0; b.Loop(); i++ {
+			_ = FamiliesWithUnit(names[i%len(names)])
+		}
+	})
+	b.Run("scan", func(b *testing.B) {
+		for i := 0; b.Loop(); i++ {
+			_ = scanFamiliesWithUnit(names[i%len(names)])
+		}
+	})
+}
+
+func TestIndexNormalisation(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		fName string
+		uName string
+		expID string
+	}{
+		{
+			ID:    testhelper.MkID("plural, any family"),
+			uName: "metres", expID: "metre",
+		},
+		{
+			ID:    testhelper.MkID("capitalised plural"),
+			uName: "Metres", expID: "metre",
+		},
+		{
+			ID:    testhelper.MkID("upper case"),
+			fName: units.Distance, uName: "KM", expID: "km",
+		},
+		{
+			ID:    testhelper.MkID("US spelling"),
+			fName: units.Volume, uName: "liters", expID: "litre",
+		},
+		{
+			ID:    testhelper.MkID("US spelling, upper case"),
+			fName: units.Distance, uName: "CENTIMETERS", expID: "cm",
+		},
+		{
+			ID:    testhelper.MkID("exact match preferred to folded"),
+			fName: units.Distance, uName: "Mm", expID: "Mm",
+		},
+		{
+			ID: testhelper.MkID("ambiguous when folded"),
+			ExpErr: testhelper.MkExpErr(
+				`there is no unit of distance called "MM"`),
+			fName: units.Distance, uName: "MM",
+		},
+	}
+
+	for _, tc := range testCases {
+		var f *units.Family
+		if tc.fName != "" {
+			f = units.GetFamilyOrPanic(tc.fName)
+		}
+
+		u, err := FindUnit(f, tc.uName)
+		if testhelper.CheckExpErr(t, err, tc) && err == nil {
+			testhelper.DiffString(t, tc.IDStr(), "unit ID", u.ID(), tc.expID)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package convert
+
+import (
+	"cmp"
+	"math"
+	"slices"
+
+	"github.com/nickwells/units.mod/v2/units"
+)
+
+// NearestCfg holds the configuration of the search for the nearest units
+type NearestCfg struct {
+	// Count is the maximum number of units to return
+	Count int
+	// Precision is how close to each other two values must be for them to
+	// be regarded as equally close to a whole number
+	Precision float64
+	// IgnoreTags gives the tags of units which should not be returned
+	IgnoreTags []units.Tag
+}
+
+// converted records a converted value and the measures used to rank it
+type converted struct {
+	vu              units.ValUnit
+	absWholeNumDiff float64
+	absLogVal       float64
+}
+
+// calcAbsWholeNumDiff calculates the absolute difference between the value and
+// the nearest whole number. Values close to simple fractions are also
+// treated as close to a whole number (see fractionDiff).
+func calcAbsWholeNumDiff(v float64) float64 {
+	multiples := []float64{2, 3, 4, 5, 8, 10}
+	awnd := fractionDiff(v, 1)
+
+	for _, m := range multiples {
+		awnd = min(fractionDiff(v, m), awnd)
+	}
+
+	return awnd
+}
+
+// calcAbsLog calculates the absolute log value of the supplied value. This
+// will give a value that is smallest when the value is equal to 1.
+func calcAbsLog(v float64) float64 {
+	return math.Abs(math.Log(v))
+}
+
+// cmpAbsLog returns -1, 0 or 1 depending on whether the absLogVal of 'a'
+// and 'b' are less than, equal to or greater than each other.
+func cmpAbsLog(a, b converted) int {
+	if a.absLogVal < b.absLogVal {
+		return -1
+	}
+
+	if a.absLogVal > b.absLogVal {
+		return 1
+	}
+
+	return 0
+}
+
+// makeCmpConvertedFunc returns a function that will compare the two
+// converted values firstly by how close each is to a whole number (note that
+// the value also includes several fractions - see calcAbsWholeNumDiff). The
+// differences are grouped into bands as wide as the precision so values
+// whose differences fall in the same band are regarded as equally close to a
+// whole number. Such values are compared by how close they are to one.
+// Finally, if they are still equal, they are compared by unit ID so that the
+// order is always the same.
+//
+// Comparing bands rather than the gap between the two differences makes
+// this a consistent ordering, so the best units can be selected without
+// ranking every unit (see nearestTopK).
+//
+// This is a generated function so that the precision can be supplied. A
+// precision of zero or less compares the differences exactly.
+func makeCmpConvertedFunc(precision float64) func(converted, converted) int {
+	band := func(c converted) float64 {
+		if precision <= 0 {
+			return c.absWholeNumDiff
+		}
+
+		return math.Floor(c.absWholeNumDiff / precision)
+	}
+
+	return func(a, b converted) int {
+		if c := cmp.Compare(band(a), band(b)); c != 0 {
+			return c
+		}
+
+		if c := cmpAbsLog(a, b); c != 0 {
+			return c
+		}
+
+		return cmp.Compare(a.vu.U.ID(), b.vu.U.ID())
+	}
+}
+
+// makeConverted converts the value into the unit and records the measures
+// used to rank it
+func makeConverted(v units.ValUnit, u units.Unit) converted {
+	vu := v.ConvertOrPanic(u)
+
+	return converted{
+		vu:              vu,
+		absWholeNumDiff: calcAbsWholeNumDiff(vu.V),
+		absLogVal:       calcAbsLog(vu.V),
+	}
+}
+
+// isNearestCandidate returns true if the unit may be returned by Nearest
+func isNearestCandidate(v units.ValUnit, u units.Unit, cfg NearestCfg) bool {
+	return !units.Equals(u, v.U) &&
+		!slices.ContainsFunc(cfg.IgnoreTags, u.HasTag)
+}
+
+// Nearest returns units from the family of the value's unit such that the
+// converted values are the closest to small, preferably whole-number
+// values. The units are ordered by small, whole numbers first and
+// fractional values second. The unit of the value itself is never
+// returned.
+func Nearest(v units.ValUnit, cfg NearestCfg) []units.Unit {
+	return nearestTopK(v, v.U.Family().GetUnits(), cfg,
+		makeCmpConvertedFunc(cfg.Precision))
+}
+
+// nearestTopK keeps only the best cfg.Count units as the units are ranked
+// so the cost is proportional to the number of units in the family times
+// the count rather than that of a full sort.
+func nearestTopK(
+	v units.ValUnit, allUnits []units.Unit, cfg NearestCfg,
+	cmpFunc func(a, b converted) int,
+) []units.Unit {
+	best := make([]converted, 0, cfg.Count+1)
+
+	for _, u := range allUnits {
+		if isNearestCandidate(v, u, cfg) {
+			best = insertTopK(best, makeConverted(v, u), cfg.Count, cmpFunc)
+		}
+	}
+
+	rval := make([]units.Unit, 0, len(best))
+	for _, c := range best {
+		rval = append(rval, c.vu.U)
+	}
+
+	return rval
+}
+
+// insertTopK inserts the value into the sorted slice, which holds no more
+// than k entries, after any entries that compare equal to it. If the slice
+// would then hold more than k entries the last entry is dropped.
+func insertTopK(
+	best []converted, c converted, k int, cmpFunc func(a, b converted) int,
+) []converted {
+	if len(best) == k && (k == 0 || cmpFunc(c, best[k-1]) >= 0) {
+		return best
+	}
+
+	i := len(best)
+	for i > 0 && cmpFunc(c, best[i-1]) < 0 {
+		i--
+	}
+
+	best = slices.Insert(best, i, c)
+	if len(best) > k {
+		best = best[:k]
+	}
+
+	return best
+}
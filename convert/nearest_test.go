@@ -0,0 +1,160 @@
+package convert
+
+import (
+	"cmp"
+	"slices"
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+	"github.com/nickwells/units.mod/v2/units"
+)
+
+func TestCalcAbsWholeNumDiff(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		v      float64
+		expVal float64
+	}{
+		{ID: testhelper.MkID("whole"), v: 3, expVal: 0},
+		{ID: testhelper.MkID("half"), v: 2.5, expVal: 0},
+		{ID: testhelper.MkID("third"), v: 1.0 / 3.0, expVal: 0},
+		{ID: testhelper.MkID("eighth"), v: 0.125, expVal: 0},
+		{ID: testhelper.MkID("not simple"), v: 0.37, expVal: 0.04},
+	}
+
+	for _, tc := range testCases {
+		testhelper.DiffFloat(t, tc.IDStr(), "absWholeNumDiff",
+			calcAbsWholeNumDiff(tc.v), tc.expVal, 1e-9)
+	}
+}
+
+// nearestFullSort finds the nearest units by ranking every unit in the
+// family with a stable sort and then skipping the units which may not be
+// returned. It uses the same ordering as Nearest and is used to check that
+// the top-k selection gives the same results and to measure the gain.
+func nearestFullSort(v units.ValUnit, cfg NearestCfg) []units.Unit {
+	allUnits := v.U.Family().GetUnits()
+	slices.SortFunc(allUnits, func(a, b units.Unit) int {
+		return cmp.Compare(a.ID(), b.ID())
+	})
+
+	unitVals := []converted{}
+
+	for _, u := range allUnits {
+		vu := v.ConvertOrPanic(u)
+		unitVals = append(unitVals, converted{
+			vu:              vu,
+			absWholeNumDiff: calcAbsWholeNumDiff(vu.V),
+			absLogVal:       calcAbsLog(vu.V),
+		})
+	}
+
+	slices.SortStableFunc(unitVals, makeCmpConvertedFunc(cfg.Precision))
+
+	rval := []units.Unit{}
+
+	for _, c := range unitVals {
+		if len(rval) >= cfg.Count {
+			break
+		}
+
+		if units.Equals(c.vu.U, v.U) ||
+			slices.ContainsFunc(cfg.IgnoreTags, c.vu.U.HasTag) {
+			continue
+		}
+
+		rval = append(rval, c.vu.U)
+	}
+
+	return rval
+}
+
+// unitIDs returns the IDs of the units
+func unitIDs(us []units.Unit) []string {
+	ids := []string{}
+	for _, u := range us {
+		ids = append(ids, u.ID())
+	}
+
+	return ids
+}
+
+func TestNearest(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		fName string
+		uName string
+		v     float64
+		cfg   NearestCfg
+	}{
+		{
+			ID:    testhelper.MkID("length"),
+			fName: units.Distance, uName: "metre", v: 10.05534,
+			cfg: NearestCfg{Count: 5, Precision: 0.01},
+		},
+		{
+			ID:    testhelper.MkID("length-ignore-tags"),
+			fName: units.Distance, uName: "metre", v: 10.05534,
+			cfg: NearestCfg{
+				Count: 5, Precision: 0.01,
+				IgnoreTags: []units.Tag{units.TagColloquial},
+			},
+		},
+		{
+			ID:    testhelper.MkID("volume"),
+			fName: units.Volume, uName: "litre", v: 4.54609,
+			cfg: NearestCfg{Count: 3, Precision: 0.01},
+		},
+		{
+			ID:    testhelper.MkID("mass-many"),
+			fName: units.Mass, uName: "kg", v: 80,
+			cfg: NearestCfg{Count: 1000, Precision: 0.05},
+		},
+		{
+			ID:    testhelper.MkID("zero-count"),
+			fName: units.Mass, uName: "kg", v: 80,
+			cfg: NearestCfg{Count: 0, Precision: 0.05},
+		},
+		{
+			ID:    testhelper.MkID("no-precision"),
+			fName: units.Time, uName: "second", v: 3600,
+			cfg: NearestCfg{Count: 4},
+		},
+		{
+			ID:    testhelper.MkID("no-precision-ignore-tags"),
+			fName: units.Distance, uName: "metre", v: 10.05534,
+			cfg: NearestCfg{
+				Count:      5,
+				IgnoreTags: []units.Tag{units.TagColloquial},
+			},
+		},
+		{
+			ID:    testhelper.MkID("no-precision-many"),
+			fName: units.Mass, uName: "kg", v: 80,
+			cfg: NearestCfg{Count: 1000},
+		},
+	}
+
+	for _, tc := range testCases {
+		v := units.ValUnit{V: tc.v, U: units.GetOrPanic(tc.fName, tc.uName)}
+
+		testhelper.DiffStringSlice(t, tc.IDStr(), "nearest units",
+			unitIDs(Nearest(v, tc.cfg)), unitIDs(nearestFullSort(v, tc.cfg)))
+	}
+}
+
+func BenchmarkNearest(b *testing.B) {
+	v := units.ValUnit{V: 10.05534, U: units.GetOrPanic(units.Distance, "m")}
+	cfg := NearestCfg{Count: 5, Precision: 0.01}
+
+	b.Run("top-k", func(b *testing.B) {
+		for b.Loop() {
+			_ = Nearest(v, cfg)
+		}
+	})
+	b.Run("full-sort", func(b *testing.B) {
+		for b.Loop() {
+			_ = nearestFullSort(v, cfg)
+		}
+	})
+}
@@ -0,0 +1,70 @@
+package convert
+
+import (
+	"math"
+	"strings"
+
+	"github.com/nickwells/units.mod/v2/units"
+)
+
+// ParseQuantity converts the string into a value in a unit, such as "1km"
+// or "2.5 feet". The number may be omitted in which case it is taken to
+// be one. If the family is nil then all the families are searched for the
+// unit as for FindUnit. It returns a CatBadValue Error if there is no
+// unit name and errors as for FindUnit if the unit cannot be found.
+//
+// The whole string is tried as a unit name first so that unit names which
+// start with a number, such as "20ft shipping container", can be given
+// without a value. Otherwise the longest number at the start of the string
+// which leaves the name of a unit is used.
+func ParseQuantity(f *units.Family, s string) (units.ValUnit, error) {
+	s = strings.TrimSpace(s)
+
+	u, err := FindUnit(f, s)
+	if err == nil {
+		return units.ValUnit{V: 1, U: u}, nil
+	}
+
+	if AsError(err).Category != CatUnknownUnit {
+		return units.ValUnit{}, err
+	}
+
+	var firstErr error
+
+	for i := len(s); i > 0; i-- {
+		v, vErr := ParseValue(s[:i])
+		if vErr != nil {
+			continue
+		}
+
+		uName := strings.TrimSpace(s[i:])
+		if uName == "" {
+			firstErr = NewError(CatBadValue,
+				"the quantity (%q) should be a number and a unit name", s)
+
+			break
+		}
+
+		u, err := FindUnit(f, uName)
+		if err == nil {
+			return units.ValUnit{V: v, U: u}, nil
+		}
+
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	if firstErr == nil {
+		firstErr = err
+	}
+
+	return units.ValUnit{}, firstErr
+}
+
+// Size returns the magnitude of the value in the base units of its family.
+// Only the conversion factor is used so, for units with offsets such as
+// temperature scales, this is the size of a difference of that value.
+func Size(v units.ValUnit) float64 {
+	return math.Abs(v.V * v.U.ConvFactor())
+}
@@ -0,0 +1,77 @@
+package convert
+
+import (
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+	"github.com/nickwells/units.mod/v2/units"
+)
+
+func TestParseQuantity(t *testing.T) {
+	length := units.GetFamilyOrPanic(units.Length)
+
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		f       *units.Family
+		s       string
+		expVal  float64
+		expUnit string
+	}{
+		{
+			ID: testhelper.MkID("no space"),
+			f:  length, s: "1km", expVal: 1, expUnit: "km",
+		},
+		{
+			ID: testhelper.MkID("with space"),
+			f:  length, s: "2.5 feet", expVal: 2.5, expUnit: "foot",
+		},
+		{
+			ID: testhelper.MkID("exponent"),
+			f:  length, s: "1e3m", expVal: 1000, expUnit: "metre",
+		},
+		{
+			ID: testhelper.MkID("no number"),
+			f:  length, s: "mile", expVal: 1, expUnit: "mile",
+		},
+		{
+			ID: testhelper.MkID("no family"),
+			s:  "3 chains", expVal: 3, expUnit: "chain",
+		},
+		{
+			ID: testhelper.MkID("unit name starts with a number"),
+			f:  length, s: "20ft shipping container",
+			expVal: 1, expUnit: "20ft shipping container",
+		},
+		{
+			ID: testhelper.MkID("number and unit name starting with a number"),
+			f:  length, s: "2 20ft shipping container",
+			expVal: 2, expUnit: "20ft shipping container",
+		},
+		{
+			ID: testhelper.MkID("ambiguous unit name starting with a number"),
+			ExpErr: testhelper.MkExpErr(
+				`there are 2 unit-families with a unit called` +
+					` "20ft shipping container"`),
+			s: "20ft shipping container",
+		},
+		{
+			ID:     testhelper.MkID("no unit"),
+			ExpErr: testhelper.MkExpErr("should be a number and a unit name"),
+			f:      length, s: "12",
+		},
+		{
+			ID:     testhelper.MkID("unknown unit"),
+			ExpErr: testhelper.MkExpErr(`"furlongs-per-fortnight"`),
+			f:      length, s: "1 furlongs-per-fortnight",
+		},
+	}
+
+	for _, tc := range testCases {
+		v, err := ParseQuantity(tc.f, tc.s)
+		if testhelper.CheckExpErr(t, err, tc) && err == nil {
+			testhelper.DiffFloat(t, tc.IDStr(), "value", v.V, tc.expVal, 0)
+			testhelper.DiffString(t, tc.IDStr(), "unit", v.U.ID(), tc.expUnit)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package convert
+
+import (
+	"strings"
+
+	"github.com/nickwells/units.mod/v2/units"
+)
+
+// RangeSep separates the two ends of a range of values given as a string
+const RangeSep = ".."
+
+// Range is an interval of values. The Lo value is never greater than the
+// Hi value.
+type Range struct {
+	Lo float64
+	Hi float64
+}
+
+// MakeRange returns the Range between the two values, given in any order
+func MakeRange(a, b float64) Range {
+	return Range{Lo: min(a, b), Hi: max(a, b)}
+}
+
+// IsRange returns true if the string gives a range of values rather than a
+// single value
+func IsRange(s string) bool {
+	return strings.Contains(s, RangeSep)
+}
+
+// ParseRange converts the string into a range of values to be converted.
+// The string should hold two values separated by RangeSep; they can be
+// given in either order. It returns a CatBadValue Error if the string is
+// not a valid range.
+func ParseRange(s string) (Range, error) {
+	loStr, hiStr, ok := strings.Cut(s, RangeSep)
+	if !ok {
+		return Range{}, NewError(CatBadValue,
+			"the range to be converted (%q) should be two values"+
+				" separated by %q",
+			s, RangeSep)
+	}
+
+	lo, err := ParseValue(loStr)
+	if err != nil {
+		return Range{}, NewError(CatBadValue,
+			"the start of the range to be converted (%q)"+
+				" is not a valid number", s)
+	}
+
+	hi, err := ParseValue(hiStr)
+	if err != nil {
+		return Range{}, NewError(CatBadValue,
+			"the end of the range to be converted (%q)"+
+				" is not a valid number", s)
+	}
+
+	return MakeRange(lo, hi), nil
+}
+
+// ConvertRange converts each end of the range from one unit into the
+// other. The ends are converted as values, not as differences, so ranges
+// of temperatures are converted correctly. The ends of the converted
+// range are sorted so that a conversion which reverses the order of
+// values still gives a valid Range.
+func ConvertRange(r Range, from, to units.Unit) (Range, error) {
+	return convertRange(r, from, to, units.ValUnit.Convert)
+}
+
+// ConvertRangeDelta is as for ConvertRange except that the ends are
+// converted as differences between two values (see ConvertDelta).
+func ConvertRangeDelta(r Range, from, to units.Unit) (Range, error) {
+	return convertRange(r, from, to, ConvertDelta)
+}
+
+// convertRange converts each end of the range using the conversion
+// function, as described for ConvertRange.
+func convertRange(
+	r Range, from, to units.Unit, conv convertFunc,
+) (Range, error) {
+	ends := [2]float64{}
+
+	for i, v := range []float64{r.Lo, r.Hi} {
+		converted, err := conv(units.ValUnit{V: v, U: from}, to)
+		if err != nil {
+			return Range{}, Error{
+				Category: CatBadConversion,
+				Unit:     to.ID(),
+				Msg:      err.Error(),
+			}
+		}
+
+		ends[i] = converted.V
+	}
+
+	return MakeRange(ends[0], ends[1]), nil
+}
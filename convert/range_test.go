@@ -0,0 +1,105 @@
+package convert
+
+import (
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+	"github.com/nickwells/units.mod/v2/units"
+)
+
+func TestParseRange(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		s        string
+		expRange Range
+	}{
+		{
+			ID:       testhelper.MkID("simple"),
+			s:        "32..104",
+			expRange: Range{Lo: 32, Hi: 104},
+		},
+		{
+			ID:       testhelper.MkID("reversed"),
+			s:        "104 .. 32",
+			expRange: Range{Lo: 32, Hi: 104},
+		},
+		{
+			ID:       testhelper.MkID("negative"),
+			s:        "-40..-10.5",
+			expRange: Range{Lo: -40, Hi: -10.5},
+		},
+		{
+			ID:     testhelper.MkID("not a range"),
+			ExpErr: testhelper.MkExpErr("should be two values"),
+			s:      "32",
+		},
+		{
+			ID:     testhelper.MkID("bad end"),
+			ExpErr: testhelper.MkExpErr("the end of the range"),
+			s:      "32..hot",
+		},
+	}
+
+	for _, tc := range testCases {
+		r, err := ParseRange(tc.s)
+		if testhelper.CheckExpErr(t, err, tc) && err == nil {
+			testhelper.DiffFloat(t, tc.IDStr(), "lo", r.Lo, tc.expRange.Lo, 0)
+			testhelper.DiffFloat(t, tc.IDStr(), "hi", r.Hi, tc.expRange.Hi, 0)
+		}
+	}
+}
+
+func TestConvertRange(t *testing.T) {
+	temp := units.GetFamilyOrPanic(units.Temperature)
+
+	testCases := []struct {
+		testhelper.ID
+		r        Range
+		from, to string
+		delta    bool
+		expRange Range
+	}{
+		{
+			ID:       testhelper.MkID("affine"),
+			r:        Range{Lo: 32, Hi: 104},
+			from:     "F",
+			to:       "C",
+			expRange: Range{Lo: 0, Hi: 40},
+		},
+		{
+			ID:       testhelper.MkID("below zero"),
+			r:        Range{Lo: -40, Hi: 0},
+			from:     "C",
+			to:       "F",
+			expRange: Range{Lo: -40, Hi: 32},
+		},
+		{
+			ID:       testhelper.MkID("delta"),
+			r:        Range{Lo: 10, Hi: 20},
+			from:     "C",
+			to:       "F",
+			delta:    true,
+			expRange: Range{Lo: 18, Hi: 36},
+		},
+	}
+
+	for _, tc := range testCases {
+		conv := ConvertRange
+		if tc.delta {
+			conv = ConvertRangeDelta
+		}
+
+		r, err := conv(tc.r,
+			temp.GetUnitOrPanic(tc.from), temp.GetUnitOrPanic(tc.to))
+		if err != nil {
+			t.Log(tc.IDStr())
+			t.Errorf("\t: unexpected error: %s", err)
+
+			continue
+		}
+
+		testhelper.DiffFloat(t, tc.IDStr(), "lo", r.Lo, tc.expRange.Lo, 1e-9)
+		testhelper.DiffFloat(t, tc.IDStr(), "hi", r.Hi, tc.expRange.Hi, 1e-9)
+	}
+}
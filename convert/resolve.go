@@ -0,0 +1,204 @@
+package convert
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/nickwells/english.mod/english"
+	"github.com/nickwells/units.mod/v2/units"
+)
+
+// FamiliesWithUnit returns the sorted names of all the families having a
+// unit with the given name.
+func FamiliesWithUnit(uName string) []string {
+	fNames := []string{}
+
+	for _, u := range getIndex().lookup(uName) {
+		fNames = append(fNames, u.Family().Name())
+	}
+
+	return fNames
+}
+
+// sortedFamilies returns all the families sorted by name
+func sortedFamilies() []*units.Family {
+	families := units.GetFamilies()
+	slices.SortFunc(families, func(a, b *units.Family) int {
+		return cmp.Compare(a.Name(), b.Name())
+	})
+
+	return families
+}
+
+// isPrimaryName returns true if the name is the ID, the name, the plural
+// name or the abbreviation of the unit rather than just one of its aliases
+func isPrimaryName(u units.Unit, uName string) bool {
+	return uName == u.ID() ||
+		uName == u.Name() ||
+		uName == u.NamePlural() ||
+		uName == u.Abbrev()
+}
+
+// preferredUnits returns those of the units which should be chosen when
+// the name matches units in more than one family. These are the units for
+// which the name is a primary name or abbreviation. If there are still
+// several, units from the dimensionless family are dropped; these are
+// scale factors whose abbreviations clash with those of units of measure.
+func preferredUnits(found []units.Unit, uName string) []units.Unit {
+	primary := slices.DeleteFunc(slices.Clone(found), func(u units.Unit) bool {
+		return !isPrimaryName(u, uName)
+	})
+	if len(primary) == 0 {
+		return found
+	}
+
+	if len(primary) > 1 {
+		measures := slices.DeleteFunc(slices.Clone(primary),
+			func(u units.Unit) bool {
+				return u.Family().Name() == units.Dimensionless
+			})
+		if len(measures) > 0 {
+			return measures
+		}
+	}
+
+	return primary
+}
+
+// FindUnit returns the named unit. If the family is nil then all the
+// families are searched. If the unit is found in more than one of them the
+// unit for which the name is a primary name or abbreviation is preferred
+// (see preferredUnits) and it is only an error if this does not settle it.
+func FindUnit(f *units.Family, uName string) (units.Unit, error) {
+	idx := getIndex()
+
+	if f != nil {
+		if u, ok := idx.lookupInFamily(f, uName); ok {
+			return u, nil
+		}
+
+		u, err := f.GetUnit(uName)
+		if err != nil {
+			return u, unknownUnitError(f, uName, err.Error())
+		}
+
+		return u, nil
+	}
+
+	found := idx.lookup(uName)
+	if len(found) > 1 {
+		found = preferredUnits(found, uName)
+	}
+
+	switch len(found) {
+	case 0:
+		return units.Unit{}, unknownUnitError(nil, uName,
+			fmt.Sprintf("there is no unit-family with a unit called %q",
+				uName))
+	case 1:
+		return found[0], nil
+	default:
+		fNames := make([]string, 0, len(found))
+		for _, u := range found {
+			fNames = append(fNames, u.Family().Name())
+		}
+
+		return units.Unit{}, Error{
+			Category: CatAmbiguousUnit,
+			Unit:     uName,
+			Families: fNames,
+			Msg: fmt.Sprintf(
+				"there are %d unit-families with a unit called %q: %s",
+				len(fNames),
+				uName,
+				english.JoinQuoted(fNames, ", ", " and ")),
+		}
+	}
+}
+
+// findUnitsInFamily finds the from and to units in the given family
+func findUnitsInFamily(
+	f *units.Family, from string, to []string,
+) (units.Unit, []units.Unit, error) {
+	fromUnit, err := FindUnit(f, from)
+	if err != nil {
+		return fromUnit, nil, err
+	}
+
+	toUnits := make([]units.Unit, 0, len(to))
+
+	for _, uName := range to {
+		u, err := FindUnit(f, uName)
+		if err != nil {
+			return fromUnit, nil, err
+		}
+
+		toUnits = append(toUnits, u)
+	}
+
+	return fromUnit, toUnits, nil
+}
+
+// commonFamily returns the first family (in alphabetical order) having
+// units with all the given names, or nil if there is no such family. It
+// returns a CatUnknownUnit Error if any name is not the name of a unit in
+// any family.
+func commonFamily(names []string) (*units.Family, error) {
+	idx := getIndex()
+
+	var common []*units.Family
+
+	for i, uName := range names {
+		found := idx.lookup(uName)
+		if len(found) == 0 {
+			return nil, unknownUnitError(nil, uName,
+				fmt.Sprintf(
+					"there is no unit-family with a unit called %q", uName))
+		}
+
+		families := make([]*units.Family, 0, len(found))
+		for _, u := range found {
+			families = append(families, u.Family())
+		}
+
+		if i == 0 {
+			common = families
+			continue
+		}
+
+		common = slices.DeleteFunc(common, func(f *units.Family) bool {
+			return !slices.Contains(families, f)
+		})
+	}
+
+	if len(common) == 0 {
+		return nil, nil
+	}
+
+	return common[0], nil
+}
+
+// FindUnits finds the from and to units. If the family is nil then the
+// first family (in alphabetical order) having all the units is used.
+func FindUnits(
+	f *units.Family, from string, to []string,
+) (units.Unit, []units.Unit, error) {
+	if f != nil {
+		return findUnitsInFamily(f, from, to)
+	}
+
+	f, err := commonFamily(append([]string{from}, to...))
+	if err != nil {
+		return units.Unit{}, nil, err
+	}
+
+	if f != nil {
+		return findUnitsInFamily(f, from, to)
+	}
+
+	return units.Unit{}, nil,
+		NewError(CatFamilyMismatch,
+			"there is no unit-family having both %q and %s",
+			from, english.JoinQuoted(to, ", ", " and "))
+}
@@ -0,0 +1,112 @@
+package convert
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+	"github.com/nickwells/units.mod/v2/units"
+)
+
+func TestFindUnit(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		family  string
+		uName   string
+		expID   string
+		expCat  Category
+		expSugg []string
+	}{
+		{
+			ID:     testhelper.MkID("found in family"),
+			family: units.Distance, uName: "metre", expID: "metre",
+		},
+		{
+			ID:    testhelper.MkID("found in one family"),
+			uName: "chain", expID: "chain",
+		},
+		{
+			ID: testhelper.MkID("misspelt, no family"),
+			ExpErr: testhelper.MkExpErr(
+				`there is no unit-family with a unit called "metr",` +
+					` did you mean "megametre", "metre" or "metric feet"?`),
+			uName:   "metr",
+			expCat:  CatUnknownUnit,
+			expSugg: []string{"megametre", "metre", "metric feet"},
+		},
+		{
+			ID: testhelper.MkID("misspelt, in family"),
+			ExpErr: testhelper.MkExpErr(
+				`there is no unit of mass called "kilogrm",` +
+					` did you mean "kilogram"?`),
+			family:  units.Mass,
+			uName:   "kilogrm",
+			expCat:  CatUnknownUnit,
+			expSugg: []string{"kilogram"},
+		},
+		{
+			ID: testhelper.MkID("misspelt plural, one suggestion per unit"),
+			ExpErr: testhelper.MkExpErr(
+				`there is no unit of distance called "inchs",` +
+					` did you mean "inch"?`),
+			family:  units.Distance,
+			uName:   "inchs",
+			expCat:  CatUnknownUnit,
+			expSugg: []string{"inch"},
+		},
+		{
+			ID: testhelper.MkID("no suggestions"),
+			ExpErr: testhelper.MkExpErr(
+				`there is no unit-family with a unit called "xyzzy"`),
+			uName:  "xyzzy",
+			expCat: CatUnknownUnit,
+		},
+		{
+			ID:    testhelper.MkID("in two families, the other is a scale factor"),
+			uName: "m", expID: "metre",
+		},
+		{
+			ID:    testhelper.MkID("in two families, the other is an alias"),
+			uName: "TEU", expID: "20ft shipping container",
+		},
+		{
+			ID: testhelper.MkID("ambiguous"),
+			ExpErr: testhelper.MkExpErr(
+				`there are 2 unit-families with a unit called "minute":` +
+					` "angle" and "time"`),
+			uName:  "minute",
+			expCat: CatAmbiguousUnit,
+		},
+	}
+
+	for _, tc := range testCases {
+		var f *units.Family
+		if tc.family != "" {
+			f = units.GetFamilyOrPanic(tc.family)
+		}
+
+		u, err := FindUnit(f, tc.uName)
+		if testhelper.CheckExpErr(t, err, tc) {
+			if err == nil {
+				testhelper.DiffString(t, tc.IDStr(), "unit ID",
+					u.ID(), tc.expID)
+
+				continue
+			}
+
+			var ce Error
+			if !errors.As(err, &ce) {
+				t.Log(tc.IDStr())
+				t.Errorf("\t: the error is not a convert.Error: %T", err)
+
+				continue
+			}
+
+			testhelper.DiffString(t, tc.IDStr(), "category",
+				string(ce.Category), string(tc.expCat))
+			testhelper.DiffStringSlice(t, tc.IDStr(), "suggestions",
+				ce.Suggestions, tc.expSugg)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package convert
+
+import (
+	"slices"
+
+	"github.com/nickwells/strdist.mod/v2/strdist"
+	"github.com/nickwells/units.mod/v2/units"
+)
+
+// names returns the sorted names by which units can be found. If the family
+// is not nil only the names of units in that family are returned.
+func (idx unitIndex) names(f *units.Family) []string {
+	names := make([]string, 0, len(idx.exact))
+
+	for name, us := range idx.exact {
+		if f == nil ||
+			slices.ContainsFunc(us, func(u units.Unit) bool {
+				return u.Family() == f
+			}) {
+			names = append(names, name)
+		}
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
+// suggestionCount is the maximum number of unit names suggested
+const suggestionCount = 3
+
+// SuggestUnitNames returns the names of units that are most like the given
+// name. The names considered are all the unit IDs, aliases, names, plural
+// names and abbreviations. Each unit is only suggested once, by whichever
+// of its names is most like the given name. If the family is not nil only
+// the names of units in that family are considered.
+func SuggestUnitNames(f *units.Family, uName string) []string {
+	type unitKey struct {
+		family *units.Family
+		id     string
+	}
+
+	idx := getIndex()
+	finder := strdist.DefaultFinders[strdist.CaseBlindAlgoNameCosine]
+	seen := map[unitKey]bool{}
+	sugg := []string{}
+
+	for _, name := range finder.FindStrLike(uName, idx.names(f)...) {
+		if len(sugg) >= suggestionCount {
+			break
+		}
+
+		isNewUnit := false
+
+		for _, u := range idx.exact[name] {
+			if f != nil && u.Family() != f {
+				continue
+			}
+
+			key := unitKey{family: u.Family(), id: u.ID()}
+			if !seen[key] {
+				seen[key] = true
+				isNewUnit = true
+			}
+		}
+
+		if isNewUnit {
+			sugg = append(sugg, name)
+		}
+	}
+
+	slices.Sort(sugg)
+
+	return sugg
+}
+
+// unknownUnitError returns a CatUnknownUnit Error for the unit name with
+// the given message. The message is extended with suggestions of similar
+// unit names, if any, which are also recorded in the Error.
+func unknownUnitError(f *units.Family, uName, msg string) Error {
+	err := Error{
+		Category:    CatUnknownUnit,
+		Unit:        uName,
+		Suggestions: SuggestUnitNames(f, uName),
+	}
+
+	if f != nil {
+		err.Families = []string{f.Name()}
+	}
+
+	err.Msg = msg + strdist.SuggestionString(slices.Clone(err.Suggestions))
+
+	return err
+}
@@ -0,0 +1,265 @@
+package convert
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+
+	"github.com/nickwells/units.mod/v2/units"
+)
+
+// System represents a system of measurement
+type System string
+
+// These are the available systems of measurement
+const (
+	SysMetric      System = "metric"
+	SysImperial    System = "imperial"
+	SysUSCustomary System = "us-customary"
+	SysSI          System = "si"
+)
+
+// systemTags maps each measurement system to the unit tag that identifies
+// units in that system
+var systemTags = map[System]units.Tag{
+	SysMetric:      units.TagMetric,
+	SysImperial:    units.TagImperial,
+	SysUSCustomary: units.TagUScustomary,
+	SysSI:          units.TagSI,
+}
+
+// Systems returns the available systems of measurement, sorted by name
+func Systems() []System {
+	return slices.Sorted(maps.Keys(systemTags))
+}
+
+// specialistTags records the tags of units which, though they may belong to
+// a measurement system, are not in general use and so are never chosen as
+// the natural unit for a value.
+var specialistTags = []units.Tag{
+	units.TagHist,
+	units.TagColloquial,
+	units.TagApothecary,
+	units.TagAstro,
+	units.TagPhysics,
+	units.TagNautical,
+	units.TagDrinks,
+	units.TagPrint,
+}
+
+// rarelyUsedUnits records, for each family, the units which, though they
+// may belong to a measurement system, are now rarely used and so are never
+// chosen as the natural unit for a value.
+var rarelyUsedUnits = map[string][]string{
+	units.Distance: {
+		"hand", "rod", "chain", "furlong", "league", "US survey foot",
+	},
+	units.Volume: {
+		"gill", "peck", "bushel",
+		"US-shot", "US-gill", "US-dry-pint", "US-dry-gallon", "US-bushel",
+		"bbl", "Mbbl", "MMbbl", "Gbbl",
+	},
+}
+
+// systemCompounds records, for each measurement system and family, the
+// units (largest first) in which a compound value is conventionally
+// expressed.
+var systemCompounds = map[System]map[string][]string{
+	SysImperial: {
+		units.Distance: {"foot", "inch"},
+		units.Mass:     {"stone", "pound"},
+		units.Volume:   {"gallon", "pint"},
+	},
+	SysUSCustomary: {
+		units.Distance: {"foot", "inch"},
+		units.Mass:     {"pound", "ounce"},
+		units.Volume:   {"US-gallon", "US-pint"},
+	},
+}
+
+// isPowerOf1000 returns true if the value is an integral power of 1000.
+func isPowerOf1000(v float64) bool {
+	const epsilon = 1e-9
+
+	p := math.Log10(v) / 3 //nolint:mnd
+
+	return math.Abs(p-math.Round(p)) < epsilon
+}
+
+// SystemUnits returns the units in the family which are in the given
+// measurement system and are suitable for showing a value in that
+// system. Units having any of the ignoreTags are not returned, nor are
+// rarely used units. The units are in size order, smallest first. For the
+// metric and SI systems only those units whose size is a power of 1000
+// times the base unit are returned; this avoids such rarely used units as
+// the decametre and the hectogram.
+func SystemUnits(
+	f *units.Family, sys System, ignoreTags []units.Tag,
+) []units.Unit {
+	tag := systemTags[sys]
+	rarelyUsed := rarelyUsedUnits[f.Name()]
+	rval := []units.Unit{}
+
+	for _, u := range f.GetUnits() {
+		if !u.HasTag(tag) ||
+			slices.ContainsFunc(specialistTags, u.HasTag) ||
+			slices.ContainsFunc(ignoreTags, u.HasTag) ||
+			slices.Contains(rarelyUsed, u.ID()) {
+			continue
+		}
+
+		if (sys == SysMetric || sys == SysSI) &&
+			!isPowerOf1000(u.ConvFactor()) {
+			continue
+		}
+
+		rval = append(rval, u)
+	}
+
+	baseName := f.BaseUnitName()
+
+	slices.SortFunc(rval, func(a, b units.Unit) int {
+		if c := cmp.Compare(a.ConvFactor(), b.ConvFactor()); c != 0 {
+			return c
+		}
+		// prefer units with a simple conversion, then the base unit
+		if aOffset, bOffset := HasOffset(a), HasOffset(b); aOffset != bOffset {
+			if bOffset {
+				return -1
+			}
+
+			return 1
+		}
+
+		if a.ID() == baseName {
+			return -1
+		}
+
+		if b.ID() == baseName {
+			return 1
+		}
+
+		return cmp.Compare(a.ID(), b.ID())
+	})
+
+	return rval
+}
+
+// naturalRange is the upper limit (exclusive) of the values which are
+// regarded as having a natural magnitude; the lower limit is one.
+const naturalRange = 1000
+
+// NaturalUnit returns the unit from the list (which must be in size order,
+// smallest first, and must not be empty) that is most natural for showing
+// the value. The units in which the value is at least one but less than a
+// thousand are ranked in the same way as for the Nearest function, using
+// the given precision, and the best one is returned. If there are no such
+// units then the largest unit in which the value is at least one is
+// returned or, if there is no such unit, the smallest unit. Negative
+// values are treated as if they were positive. Where several units have the
+// same size only the first one is considered.
+func NaturalUnit(
+	v units.ValUnit, sysUnits []units.Unit, precision float64,
+) units.Unit {
+	fallback := sysUnits[0]
+	unitVals := make([]converted, 0, len(sysUnits))
+
+	for i, u := range sysUnits {
+		if i > 0 && u.ConvFactor() == sysUnits[i-1].ConvFactor() {
+			continue
+		}
+
+		vu := v.ConvertOrPanic(u)
+
+		absV := math.Abs(vu.V)
+		if absV < 1 {
+			break
+		}
+
+		fallback = u
+
+		if absV < naturalRange {
+			unitVals = append(unitVals, converted{
+				vu:              vu,
+				absWholeNumDiff: calcAbsWholeNumDiff(absV),
+				absLogVal:       calcAbsLog(absV),
+			})
+		}
+	}
+
+	if len(unitVals) == 0 {
+		return fallback
+	}
+
+	slices.SortStableFunc(unitVals, makeCmpConvertedFunc(precision))
+
+	return unitVals[0].vu.U
+}
+
+// CompoundUnits returns the units in which a compound value in the given
+// measurement system should be shown. Leading units in which the value
+// would be less than one are dropped but the last unit is always retained.
+func CompoundUnits(v units.ValUnit, sys System) ([]units.Unit, error) {
+	f := v.U.Family()
+
+	uNames, ok := systemCompounds[sys][f.Name()]
+	if !ok {
+		return nil,
+			NewError(CatFamilyMismatch,
+				"there is no compound form for a %s in the %s system",
+				f.Description(), sys)
+	}
+
+	rval := []units.Unit{}
+
+	for i, uName := range uNames {
+		u, err := f.GetUnit(uName)
+		if err != nil {
+			return nil, NewError(CatUnknownUnit, "%s", err)
+		}
+
+		if len(rval) == 0 && i != len(uNames)-1 {
+			cv, err := v.Convert(u)
+			if err != nil {
+				return nil, NewError(CatBadConversion, "%s", err)
+			}
+
+			if math.Abs(cv.V) < 1 {
+				continue
+			}
+		}
+
+		rval = append(rval, u)
+	}
+
+	return rval, nil
+}
+
+// SystemTargets returns the most natural unit in the given measurement
+// system for showing the value or, if compound is true, the units in which
+// the compound value should be shown. The precision is used to rank the
+// units as for the Nearest function (see NaturalUnit).
+func SystemTargets(
+	v units.ValUnit, sys System, compound bool,
+	precision float64, ignoreTags []units.Tag,
+) ([]units.Unit, error) {
+	if compound {
+		return CompoundUnits(v, sys)
+	}
+
+	f := v.U.Family()
+
+	sysUnits := SystemUnits(f, sys, ignoreTags)
+	if len(sysUnits) == 0 {
+		return nil, Error{
+			Category: CatFamilyMismatch,
+			Families: []string{f.Name()},
+			Msg: fmt.Sprintf("there is no %s in the %s system",
+				f.Description(), sys),
+		}
+	}
+
+	return []units.Unit{NaturalUnit(v, sysUnits, precision)}, nil
+}
@@ -0,0 +1,178 @@
+package convert
+
+import (
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+	"github.com/nickwells/units.mod/v2/units"
+)
+
+func TestIsPowerOf1000(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		v      float64
+		expVal bool
+	}{
+		{ID: testhelper.MkID("one"), v: 1, expVal: true},
+		{ID: testhelper.MkID("thousand"), v: 1000, expVal: true},
+		{ID: testhelper.MkID("thousandth"), v: 0.001, expVal: true},
+		{ID: testhelper.MkID("million"), v: 1e6, expVal: true},
+		{ID: testhelper.MkID("hundredth"), v: 0.01, expVal: false},
+		{ID: testhelper.MkID("ten"), v: 10, expVal: false},
+	}
+
+	for _, tc := range testCases {
+		testhelper.DiffBool(t, tc.IDStr(), "isPowerOf1000",
+			isPowerOf1000(tc.v), tc.expVal)
+	}
+}
+
+func TestNaturalUnit(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		fName string
+		from  string
+		v     float64
+		sys   System
+		expID string
+	}{
+		{
+			ID:    testhelper.MkID("metric-small"),
+			fName: units.Distance, from: "metre", v: 0.002, sys: SysMetric,
+			expID: "mm",
+		},
+		{
+			ID:    testhelper.MkID("metric-large"),
+			fName: units.Distance, from: "metre", v: 2500, sys: SysMetric,
+			expID: "km",
+		},
+		{
+			ID:    testhelper.MkID("metric-whole-number-preferred"),
+			fName: units.Mass, from: "kg", v: 0.25, sys: SysMetric,
+			expID: "gram",
+		},
+		{
+			ID:    testhelper.MkID("imperial-rarely-used-units-skipped"),
+			fName: units.Distance, from: "metre", v: 300, sys: SysImperial,
+			expID: "foot",
+		},
+		{
+			ID:    testhelper.MkID("imperial-natural-magnitude"),
+			fName: units.Distance, from: "metre", v: 5000, sys: SysImperial,
+			expID: "mile",
+		},
+		{
+			ID:    testhelper.MkID("imperial-negative"),
+			fName: units.Distance, from: "metre", v: -5000, sys: SysImperial,
+			expID: "mile",
+		},
+		{
+			ID:    testhelper.MkID("imperial-too-large"),
+			fName: units.Distance, from: "metre", v: 5e7, sys: SysImperial,
+			expID: "mile",
+		},
+		{
+			ID:    testhelper.MkID("imperial-too-small"),
+			fName: units.Distance, from: "metre", v: 0.001, sys: SysImperial,
+			expID: "inch",
+		},
+		{
+			ID:    testhelper.MkID("imperial-mass"),
+			fName: units.Mass, from: "kg", v: 80, sys: SysImperial,
+			expID: "stone",
+		},
+		{
+			ID:    testhelper.MkID("us-customary-volume"),
+			fName: units.Volume, from: "litre", v: 3, sys: SysUSCustomary,
+			expID: "US-pint",
+		},
+	}
+
+	for _, tc := range testCases {
+		f := units.GetFamilyOrPanic(tc.fName)
+		v := units.ValUnit{V: tc.v, U: f.GetUnitOrPanic(tc.from)}
+
+		u := NaturalUnit(v, SystemUnits(f, tc.sys, nil), 0.01)
+		testhelper.DiffString(t, tc.IDStr(), "unit", u.ID(), tc.expID)
+	}
+}
+
+func TestSystemTargets(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		fName    string
+		from     string
+		v        float64
+		sys      System
+		compound bool
+		expIDs   []string
+	}{
+		{
+			ID:   testhelper.MkID("metric-small"),
+			from: "metre", v: 0.002, sys: SysMetric,
+			expIDs: []string{"mm"},
+		},
+		{
+			ID:   testhelper.MkID("metric-large"),
+			from: "metre", v: 2500, sys: SysMetric,
+			expIDs: []string{"km"},
+		},
+		{
+			ID:   testhelper.MkID("imperial"),
+			from: "metre", v: 300, sys: SysImperial,
+			expIDs: []string{"foot"},
+		},
+		{
+			ID:   testhelper.MkID("imperial-compound"),
+			from: "metre", v: 1.8, sys: SysImperial, compound: true,
+			expIDs: []string{"foot", "inch"},
+		},
+		{
+			ID:   testhelper.MkID("imperial-compound-small"),
+			from: "metre", v: 0.1, sys: SysImperial, compound: true,
+			expIDs: []string{"inch"},
+		},
+		{
+			ID:   testhelper.MkID("us-customary-compound"),
+			from: "metre", v: 2, sys: SysUSCustomary, compound: true,
+			expIDs: []string{"foot", "inch"},
+		},
+		{
+			ID: testhelper.MkID("no-units-in-system"),
+			ExpErr: testhelper.MkExpErr(
+				"there is no unit of temperature in the imperial system"),
+			fName: units.Temperature,
+			from:  "C", v: 20, sys: SysImperial,
+		},
+		{
+			ID: testhelper.MkID("no-compound-form"),
+			ExpErr: testhelper.MkExpErr(
+				"there is no compound form for a unit of temperature" +
+					" in the imperial system"),
+			fName: units.Temperature,
+			from:  "C", v: 20, sys: SysImperial, compound: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		fName := tc.fName
+		if fName == "" {
+			fName = units.Distance
+		}
+
+		v := units.ValUnit{V: tc.v, U: units.GetOrPanic(fName, tc.from)}
+
+		us, err := SystemTargets(v, tc.sys, tc.compound, 0.01, nil)
+		if !testhelper.CheckExpErr(t, err, tc) || err != nil {
+			continue
+		}
+
+		ids := []string{}
+		for _, u := range us {
+			ids = append(ids, u.ID())
+		}
+
+		testhelper.DiffStringSlice(t, tc.IDStr(), "units", ids, tc.expIDs)
+	}
+}
@@ -0,0 +1,44 @@
+package convert
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/nickwells/units.mod/v2/units"
+)
+
+// ParseValue converts the string into a value to be converted. It returns
+// a CatBadValue Error if the string is not a valid, finite number.
+func ParseValue(s string) (float64, error) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, NewError(CatBadValue,
+			"the value to be converted (%q) is not a valid number", s)
+	}
+
+	return v, nil
+}
+
+// CheckPrecision returns a CatPrecisionLoss Error if the value would be
+// shown as zero with the given number of decimal places even though it is
+// not zero.
+func CheckPrecision(v units.ValUnit, prec int) error {
+	if v.V == 0 {
+		return nil
+	}
+
+	if math.Abs(v.V) < math.Pow10(-prec)/2 { //nolint:mnd
+		return Error{
+			Category: CatPrecisionLoss,
+			Unit:     v.U.ID(),
+			Msg: fmt.Sprintf(
+				"the value in %s (%g) is too small"+
+					" to show with a precision of %d",
+				v.U.NamePlural(), v.V, prec),
+		}
+	}
+
+	return nil
+}
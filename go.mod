@@ -7,28 +7,28 @@ require (
 	github.com/nickwells/col.mod/v6 v6.1.1
 	github.com/nickwells/english.mod v1.2.10
 	github.com/nickwells/errutil.mod v1.2.24
+	github.com/nickwells/filecheck.mod v1.2.13
+	github.com/nickwells/fileparse.mod v1.1.39
+	github.com/nickwells/location.mod v1.2.37
 	github.com/nickwells/mathutil.mod/v2 v2.5.11
 	github.com/nickwells/param.mod/v7 v7.2.4
+	github.com/nickwells/strdist.mod/v2 v2.1.2
 	github.com/nickwells/testhelper.mod/v2 v2.6.1
 	github.com/nickwells/twrap.mod v1.5.14
 	github.com/nickwells/units.mod/v2 v2.4.0
 	github.com/nickwells/unitsetter.mod/v4 v4.4.0
 	github.com/nickwells/verbose.mod v1.1.24
 	github.com/nickwells/versionparams.mod v1.2.28
+	github.com/nickwells/xdg.mod v1.0.12
 )
 
 require (
-	github.com/nickwells/strdist.mod/v2 v2.1.2 // indirect
 	github.com/nickwells/tempus.mod v1.2.11 // indirect
 )
 
 require (
-	github.com/nickwells/filecheck.mod v1.2.13 // indirect
-	github.com/nickwells/fileparse.mod v1.1.39 // indirect
-	github.com/nickwells/location.mod v1.2.37 // indirect
 	github.com/nickwells/pager.mod v1.1.0 // indirect
 	github.com/nickwells/timer.mod v1.2.7 // indirect
-	github.com/nickwells/xdg.mod v1.0.12 // indirect
 	golang.org/x/exp v0.0.0-20260611194520-c48552f49976 // indirect
 	golang.org/x/sys v0.46.0 // indirect
 	golang.org/x/term v0.44.0 // indirect
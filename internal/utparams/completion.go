@@ -0,0 +1,441 @@
+package utparams
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/nickwells/location.mod/location"
+	"github.com/nickwells/param.mod/v7/param"
+	"github.com/nickwells/param.mod/v7/psetter"
+	"github.com/nickwells/units.mod/v2/units"
+)
+
+// Shell is the name of a shell for which a completion script can be
+// generated
+type Shell string
+
+// These are the shells for which completion scripts can be generated
+const (
+	ShellBash Shell = "bash"
+	ShellZsh  Shell = "zsh"
+	ShellFish Shell = "fish"
+)
+
+// CompletionKind describes the kind of value that a parameter takes
+type CompletionKind string
+
+// These are the kinds of parameter value that can be completed. The
+// values of a CompleteParamVals parameter are given by the Vals func of
+// its CompletionArg.
+const (
+	CompleteFamily    CompletionKind = "family"
+	CompleteUnit      CompletionKind = "unit"
+	CompleteTag       CompletionKind = "tag"
+	CompleteParamVals CompletionKind = "param"
+)
+
+// CompletionArg records a parameter whose value can be completed
+type CompletionArg struct {
+	// ParamName is the name of the parameter, its alternative names will
+	// also be completed
+	ParamName string
+	// Kind is the kind of value the parameter takes
+	Kind CompletionKind
+	// IsList is set if the parameter takes a comma-separated list of
+	// values
+	IsList bool
+	// Vals returns the values to be completed if the Kind is
+	// CompleteParamVals
+	Vals func() []string
+}
+
+// listKind returns the kind of values to be listed for the parameter, as
+// given to the completion-list parameter
+func (a CompletionArg) listKind() string {
+	if a.Kind == CompleteParamVals {
+		return string(a.Kind) + ":" + a.ParamName
+	}
+
+	return string(a.Kind)
+}
+
+// These are the names of the completion parameters
+const (
+	paramNameCompletionScript = "completion-script"
+	paramNameCompletionList   = "completion-list"
+
+	completionGroupName = "completion"
+)
+
+// completionArgNames is the completion detail used to generate a script
+type completionArgNames struct {
+	Names  []string
+	Kind   string
+	IsList bool
+}
+
+// completionScript holds the values used to generate a completion script
+type completionScript struct {
+	ProgName    string
+	FuncName    string
+	ListParam   string
+	FamilyNames []string
+	Args        []completionArgNames
+}
+
+// AddCompletionParams returns a PSetOptFunc which adds the parameters used
+// to generate shell completion scripts for the named program and to list
+// the values to be completed. The args give the parameters whose values
+// can be completed.
+//
+// Both parameters write their output to the standard output and then exit
+// the program immediately without checking any other parameters; this is
+// so that they can be used without having to give otherwise required
+// parameters.
+func AddCompletionParams(
+	progName string, args ...CompletionArg,
+) param.PSetOptFunc {
+	return func(ps *param.PSet) error {
+		ps.AddGroup(completionGroupName,
+			"parameters relating to the completion of"+
+				" parameter values by the shell.")
+
+		var shell Shell
+
+		ps.Add(paramNameCompletionScript,
+			psetter.Enum[Shell]{
+				Value: &shell,
+				AllowedVals: psetter.AllowedVals[Shell]{
+					ShellBash: "generate a script for the bash shell",
+					ShellZsh:  "generate a script for the zsh shell",
+					ShellFish: "generate a script for the fish shell",
+				},
+				AllowInvalidInitialValue: true,
+			},
+			"write a script to the standard output which,"+
+				" when loaded into the shell, will complete the"+
+				" values of the parameters taking family, unit"+
+				" or tag names and then exit. Unit names are completed"+
+				" from the family already given, if any."+
+				"\n\n"+
+				"For instance, for bash, add the following"+
+				" to your .bashrc file:"+
+				"\n\n"+
+				"source <("+progName+
+				" -"+paramNameCompletionScript+" bash)",
+			param.PostAction(
+				func(_ location.L, _ *param.BaseParam, _ []string) error {
+					err := WriteCompletionScript(os.Stdout,
+						shell, ps, progName, args...)
+					if err != nil {
+						return err
+					}
+
+					os.Exit(0)
+
+					return nil
+				}),
+			param.ValueName("shell"),
+			param.Attrs(param.CommandLineOnly|param.DontShowInStdUsage),
+			param.GroupName(completionGroupName),
+		)
+
+		var listKind string
+
+		ps.Add(paramNameCompletionList,
+			psetter.String[string]{Value: &listKind},
+			"write the values to be completed for the given kind"+
+				" of parameter to the standard output,"+
+				" one per line, and then exit."+
+				" The kind is one of "+
+				fmt.Sprintf("%q, %q, %q or %q",
+					CompleteFamily, CompleteUnit, CompleteTag,
+					CompleteParamVals)+
+				". The unit kind may be followed by a colon"+
+				" and a family name in which case only the units"+
+				" in that family are listed. The "+
+				fmt.Sprintf("%q", CompleteParamVals)+
+				" kind must be followed by a colon and the name"+
+				" of the parameter whose values are to be listed."+
+				"\n\n"+
+				"This is used by the completion scripts.",
+			param.PostAction(
+				func(_ location.L, _ *param.BaseParam, _ []string) error {
+					if err := WriteCompletionList(os.Stdout,
+						listKind, args...); err != nil {
+						return err
+					}
+
+					os.Exit(0)
+
+					return nil
+				}),
+			param.ValueName("kind[:family|param]"),
+			param.Attrs(param.CommandLineOnly|param.DontShowInStdUsage),
+			param.GroupName(completionGroupName),
+		)
+
+		return nil
+	}
+}
+
+// WriteCompletionList writes the values to be completed for the given kind
+// of parameter, one per line, in alphabetical order. A unit kind may be
+// followed by a colon and a family name to restrict the units to that
+// family; if the family is empty or not recognised the units from all the
+// families are written. A param kind must be followed by a colon and the
+// name of one of the parameters in the args whose values are written.
+func WriteCompletionList(
+	w io.Writer, kind string, args ...CompletionArg,
+) error {
+	var vals []string
+
+	kind, qualifier, _ := strings.Cut(kind, ":")
+
+	switch CompletionKind(kind) {
+	case CompleteFamily:
+		vals = units.GetFamilyNames()
+	case CompleteUnit:
+		if f, err := units.GetFamily(qualifier); err == nil {
+			vals = f.GetUnitNames()
+		} else {
+			for _, f := range units.GetFamilies() {
+				vals = append(vals, f.GetUnitNames()...)
+			}
+		}
+	case CompleteTag:
+		vals = units.GetTagNames()
+	case CompleteParamVals:
+		i := slices.IndexFunc(args, func(a CompletionArg) bool {
+			return a.Kind == CompleteParamVals && a.ParamName == qualifier
+		})
+		if i < 0 || args[i].Vals == nil {
+			return fmt.Errorf("no values can be completed"+
+				" for the parameter: %q", qualifier)
+		}
+
+		vals = args[i].Vals()
+	default:
+		return fmt.Errorf("unknown completion kind: %q", kind)
+	}
+
+	slices.Sort(vals)
+
+	for _, v := range slices.Compact(vals) {
+		fmt.Fprintln(w, v)
+	}
+
+	return nil
+}
+
+// paramNames returns the names by which the parameter can be given,
+// including the alternative names, each with a leading '-'. Each name is
+// only returned once.
+func paramNames(ps *param.PSet, name string) ([]string, error) {
+	p, err := ps.GetParamByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, n := range append([]string{p.Name()}, p.AltNames()...) {
+		names = append(names, "-"+n)
+	}
+
+	slices.Sort(names)
+
+	return slices.Compact(names), nil
+}
+
+// WriteCompletionScript writes the completion script for the given shell
+// to the writer. The parameter names are looked up in the PSet so that
+// their alternative names will also be completed.
+func WriteCompletionScript(
+	w io.Writer, shell Shell, ps *param.PSet,
+	progName string, args ...CompletionArg,
+) error {
+	tmpl, ok := completionTemplates[shell]
+	if !ok {
+		return fmt.Errorf("there is no completion script for the %q shell",
+			shell)
+	}
+
+	cs := completionScript{
+		ProgName:  progName,
+		FuncName:  "_" + strings.ReplaceAll(progName, "-", "_") + "_complete",
+		ListParam: "-" + paramNameCompletionList,
+	}
+
+	familyNames := map[string]bool{}
+
+	for _, a := range args {
+		names, err := paramNames(ps, a.ParamName)
+		if err != nil {
+			return err
+		}
+
+		cs.Args = append(cs.Args, completionArgNames{
+			Names:  names,
+			Kind:   a.listKind(),
+			IsList: a.IsList,
+		})
+
+		if a.Kind == CompleteFamily {
+			for _, n := range names {
+				familyNames[n] = true
+			}
+		}
+	}
+
+	cs.FamilyNames = slices.Sorted(maps.Keys(familyNames))
+
+	return tmpl.Execute(w, cs)
+}
+
+// completionFuncs are the functions available to the completion templates
+var completionFuncs = template.FuncMap{
+	"join": strings.Join,
+}
+
+// completionTemplates holds the templates for the completion scripts for
+// each shell
+var completionTemplates = map[Shell]*template.Template{
+	ShellBash: template.Must(template.New("bash").
+		Funcs(completionFuncs).Parse(bashTemplate)),
+	ShellZsh: template.Must(template.New("zsh").
+		Funcs(completionFuncs).Parse(zshTemplate)),
+	ShellFish: template.Must(template.New("fish").
+		Funcs(completionFuncs).Parse(fishTemplate)),
+}
+
+const bashTemplate = `# bash completion for {{.ProgName}}
+{{.FuncName}}() {
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+    local prev="${COMP_WORDS[COMP_CWORD-1]}"
+    local family="" kind="" pfx="" i
+{{- if .FamilyNames}}
+
+    for (( i=1; i < COMP_CWORD-1; i++ )); do
+        case "${COMP_WORDS[i]}" in
+        {{join .FamilyNames "|"}})
+            family="${COMP_WORDS[i+1]}" ;;
+        esac
+    done
+{{- end}}
+
+    case "$prev" in
+{{- range .Args}}
+    {{join .Names "|"}})
+        kind="{{.Kind}}"
+{{- if .IsList}}
+        if [[ "$cur" == *,* ]]; then
+            pfx="${cur%,*},"
+            cur="${cur##*,}"
+        fi
+{{- end}}
+        ;;
+{{- end}}
+    *)
+        return 1 ;;
+    esac
+
+    if [[ "$kind" == "unit" ]]; then
+        kind="unit:$family"
+    fi
+
+    # values may contain spaces and other special characters so they are
+    # matched against the unquoted word and quoted when completed
+    local v
+    cur="${cur#[\"\']}"
+    cur="${cur//\\/}"
+    COMPREPLY=()
+    while IFS= read -r v; do
+        if [[ "$v" == "$cur"* ]]; then
+            COMPREPLY+=( "$pfx$(printf '%q' "$v")" )
+        fi
+    done < <({{.ProgName}} {{.ListParam}} "$kind" 2>/dev/null)
+}
+complete -o default -F {{.FuncName}} {{.ProgName}}
+`
+
+const zshTemplate = `#compdef {{.ProgName}}
+# zsh completion for {{.ProgName}}
+{{.FuncName}}() {
+    local prev="${words[CURRENT-1]}"
+    local family="" kind="" i
+    local -a vals
+{{- if .FamilyNames}}
+
+    for (( i=2; i < CURRENT-1; i++ )); do
+        case "${words[i]}" in
+        ({{join .FamilyNames "|"}})
+            family="${words[i+1]}" ;;
+        esac
+    done
+{{- end}}
+
+    case "$prev" in
+{{- range .Args}}
+    ({{join .Names "|"}})
+        kind="{{.Kind}}"
+{{- if .IsList}}
+        compset -P '*,'
+{{- end}}
+        ;;
+{{- end}}
+    (*)
+        _default
+        return ;;
+    esac
+
+    if [[ "$kind" == "unit" ]]; then
+        kind="unit:$family"
+    fi
+
+    # compadd quotes any spaces or other special characters in the values
+    vals=( ${(f)"$({{.ProgName}} {{.ListParam}} "$kind" 2>/dev/null)"} )
+    compadd -a vals
+}
+compdef {{.FuncName}} {{.ProgName}}
+`
+
+const fishTemplate = `# fish completion for {{.ProgName}}
+function {{.FuncName}}_family
+    set -l family ""
+{{- if .FamilyNames}}
+    set -l tokens (commandline -opc)
+    for i in (seq 2 (math (count $tokens) - 1))
+        switch $tokens[$i]
+        case {{join .FamilyNames " "}}
+            set family $tokens[(math $i + 1)]
+        end
+    end
+{{- end}}
+    echo $family
+end
+
+function {{.FuncName}}_list
+    set -l kind $argv[1]
+    if test "$kind" = unit
+        set kind "unit:"({{.FuncName}}_family)
+    end
+    set -l pfx ""
+    if test "$argv[2]" = list
+        set pfx (string match -r '^.*,' -- (commandline -ct))
+    end
+    # fish escapes any spaces or other special characters in the values
+    for v in ({{.ProgName}} {{.ListParam}} $kind 2>/dev/null)
+        echo "$pfx$v"
+    end
+end
+{{range $a := .Args}}
+{{- range .Names}}
+complete -c {{$.ProgName}} -o {{slice . 1}} -x -a '({{$.FuncName}}_list {{$a.Kind}}{{if $a.IsList}} list{{end}})'
+{{- end}}
+{{- end}}
+`
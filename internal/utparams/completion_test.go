@@ -0,0 +1,183 @@
+package utparams
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/nickwells/param.mod/v7/param"
+	"github.com/nickwells/param.mod/v7/paramset"
+	"github.com/nickwells/param.mod/v7/psetter"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+var gfc = testhelper.GoldenFileCfg{
+	DirNames:               []string{"testdata", "completion"},
+	Sfx:                    "txt",
+	UpdFlagName:            "upd-gf",
+	KeepBadResultsFlagName: "keep-bad-results",
+}
+
+func init() {
+	gfc.AddUpdateFlag()
+	gfc.AddKeepBadResultsFlag()
+}
+
+// testCompletionArgs are the completion arguments used in the tests
+var testCompletionArgs = []CompletionArg{
+	{ParamName: "family", Kind: CompleteFamily},
+	{ParamName: "from", Kind: CompleteUnit},
+	{ParamName: "to", Kind: CompleteUnit, IsList: true},
+	{ParamName: "tag", Kind: CompleteTag, IsList: true},
+	{
+		ParamName: "colour",
+		Kind:      CompleteParamVals,
+		Vals:      func() []string { return []string{"red", "light blue"} },
+	},
+}
+
+// makeTestPSet returns a PSet having the parameters given in the
+// testCompletionArgs
+func makeTestPSet() *param.PSet {
+	var s string
+
+	return paramset.NewNoHelpNoExitNoErrRpt(
+		func(ps *param.PSet) error {
+			ps.Add("family", psetter.String[string]{Value: &s}, "family",
+				param.AltNames("f", "fam"))
+			ps.Add("from", psetter.String[string]{Value: &s}, "from")
+			ps.Add("to", psetter.String[string]{Value: &s}, "to")
+			ps.Add("tag", psetter.String[string]{Value: &s}, "tag",
+				param.AltNames("t"))
+			ps.Add("colour", psetter.String[string]{Value: &s}, "colour")
+
+			return nil
+		},
+		AddCompletionParams("testprog", testCompletionArgs...),
+	)
+}
+
+func TestWriteCompletionScript(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		shell Shell
+		args  []CompletionArg
+	}{
+		{
+			ID:    testhelper.MkID("bash"),
+			shell: ShellBash,
+			args:  testCompletionArgs,
+		},
+		{
+			ID:    testhelper.MkID("zsh"),
+			shell: ShellZsh,
+			args:  testCompletionArgs,
+		},
+		{
+			ID:    testhelper.MkID("fish"),
+			shell: ShellFish,
+			args:  testCompletionArgs,
+		},
+		{
+			ID:    testhelper.MkID("fish-no-family"),
+			shell: ShellFish,
+			args:  testCompletionArgs[3:4],
+		},
+		{
+			ID: testhelper.MkID("bad-shell"),
+			ExpErr: testhelper.MkExpErr(
+				`there is no completion script for the "csh" shell`),
+			shell: "csh",
+			args:  testCompletionArgs,
+		},
+		{
+			ID:     testhelper.MkID("bad-param"),
+			ExpErr: testhelper.MkExpErr(`"nonesuch"`),
+			shell:  ShellBash,
+			args: []CompletionArg{
+				{ParamName: "nonesuch", Kind: CompleteTag},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		var buf bytes.Buffer
+
+		err := WriteCompletionScript(&buf, tc.shell, makeTestPSet(),
+			"testprog", tc.args...)
+		if testhelper.CheckExpErr(t, err, tc) && err == nil {
+			gfc.Check(t, tc.IDStr(), tc.Name, buf.Bytes())
+		}
+	}
+}
+
+func TestWriteCompletionList(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		kind    string
+		expVals []string
+	}{
+		{
+			ID:   testhelper.MkID("angle units"),
+			kind: "unit:angle",
+			expVals: []string{
+				"degree", "gradian", "milliradian", "minute",
+				"radian", "second",
+			},
+		},
+		{
+			ID:      testhelper.MkID("unit in family alias"),
+			kind:    "unit:length",
+			expVals: []string{"mile"},
+		},
+		{
+			ID:      testhelper.MkID("all units"),
+			kind:    "unit",
+			expVals: []string{"degree", "mile", "pint"},
+		},
+		{
+			ID:      testhelper.MkID("families"),
+			kind:    "family",
+			expVals: []string{"angle", "distance", "volume"},
+		},
+		{
+			ID:      testhelper.MkID("tags"),
+			kind:    "tag",
+			expVals: []string{"metric", "imperial"},
+		},
+		{
+			ID:     testhelper.MkID("bad kind"),
+			ExpErr: testhelper.MkExpErr(`unknown completion kind: "nonesuch"`),
+			kind:   "nonesuch",
+		},
+		{
+			ID:      testhelper.MkID("param values"),
+			kind:    "param:colour",
+			expVals: []string{"light blue", "red"},
+		},
+		{
+			ID: testhelper.MkID("param without values"),
+			ExpErr: testhelper.MkExpErr(
+				`no values can be completed for the parameter: "tag"`),
+			kind: "param:tag",
+		},
+	}
+
+	for _, tc := range testCases {
+		var buf bytes.Buffer
+
+		err := WriteCompletionList(&buf, tc.kind, testCompletionArgs...)
+		if testhelper.CheckExpErr(t, err, tc) && err == nil {
+			vals := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			for _, v := range tc.expVals {
+				if !slices.Contains(vals, v) {
+					t.Log(tc.IDStr())
+					t.Errorf("\t: %q was not in the list of values", v)
+				}
+			}
+		}
+	}
+}
@@ -18,6 +18,24 @@ func addExamples(ps *param.PSet) error {
 	ps.AddExample("unitconv -from chain -to m -val 80 -roughly",
 		"This will show 80 chains in metres. The value is "+
 			"adjusted to show the nearest multiple of 5 or 10")
+	ps.AddExample("unitconv -f length -from m -val 2500 -to-system metric",
+		"This will show 2500 metres in the most natural metric unit"+
+			" (kilometres)")
+	ps.AddExample(
+		"unitconv -f length -from m -val 1.8 -to-system imperial -compound",
+		"This will show 1.8 metres in feet and inches")
+	ps.AddExample("unitconv -from F -to C -val 32..104",
+		"This will show the range from 32 to 104 degrees Fahrenheit"+
+			" in degrees Celsius")
+	ps.AddExample("unitconv -serve localhost:8080",
+		"This will run unitconv as an HTTP service."+
+			" A request to"+
+			" http://localhost:8080/convert?from=chain&to=m&value=80"+
+			" will then return 80 chains in metres as JSON")
+	ps.AddExample("unitconv -coprocess < requests.ndjson",
+		"This will read the conversion requests,"+
+			" one JSON object per line,"+
+			" and write a JSON response for each one")
 
 	return nil
 }
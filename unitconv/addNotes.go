@@ -1,13 +1,24 @@
 package main
 
 import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+
 	"github.com/nickwells/param.mod/v7/param"
+	"github.com/nickwells/unittools/convert"
 )
 
 const (
 	noteBaseName = "unitconv - "
 
-	noteNameNearest = noteBaseName + "nearest conversion"
+	noteNameNearest    = noteBaseName + "nearest conversion"
+	noteNameToSystem   = noteBaseName + "systems of measurement"
+	noteNameExitStatus = noteBaseName + "exit statuses"
+	noteNameServe      = noteBaseName + "HTTP service"
+	noteNameCoprocess  = noteBaseName + "co-process"
+	noteNamePresets    = noteBaseName + "presets"
 )
 
 // addNotes adds the notes for this program.
@@ -26,6 +37,156 @@ func addNotes(_ *prog) param.PSetOptFunc {
 				" '"+paramNameRoughly+"' parameter) can help"+
 				" you identify this as two rods or half a chain.")
 
+		ps.AddNote(noteNameToSystem,
+			"if you pass the program the '"+paramNameToSystem+"'"+
+				" parameter then it will choose the unit to convert"+
+				" into from those units in the family which have"+
+				" the unit tag for that system of measurement."+
+				" The units in which the value is at least one"+
+				" but less than a thousand are ranked in the same"+
+				" way as for the '"+paramNameNearest+"' parameter"+
+				" and the best one is chosen,"+
+				" so 0.002 metres will be shown as 2 millimetres"+
+				" and 2500 metres as 2.5 kilometres."+
+				"\n\n"+
+				"With the '"+paramNameCompound+"' parameter the value"+
+				" is shown in the units conventionally combined"+
+				" for that system and family. For instance"+
+				" imperial lengths are shown in feet and inches and"+
+				" imperial masses in stones and pounds.",
+			param.NoteSeeParam(paramNameToSystem, paramNameCompound))
+
+		ps.AddNote(noteNameServe,
+			"if you pass the program the '"+paramNameServe+"'"+
+				" parameter then it will run as an HTTP service."+
+				" The service has the following endpoints,"+
+				" each of which responds to GET requests"+
+				" with a JSON body:"+
+				"\n\n"+
+				"/convert?value=V&from=U&to=U,...&family=F"+
+				" - convert the value (default 1) between the units."+
+				" If more than one 'to' unit is given a compound"+
+				" value is returned. The 'roughly' and 'very-roughly'"+
+				" query parameters may be set to true to round"+
+				" the converted values."+
+				"\n\n"+
+				"/nearest?value=V&from=U&family=F&count=N&ignore-tag=T"+
+				" - show the value in the units where it is closest"+
+				" to a small, whole number."+
+				" The 'ignore-tag' parameter may be repeated."+
+				"\n\n"+
+				"/families - list the families of units."+
+				"\n\n"+
+				"/units?family=F - list the units in the family."+
+				"\n\n"+
+				"Any errors are reported as a JSON record"+
+				" giving the error category and the message,"+
+				" as with the '"+paramNameErrorsAsJSON+"' parameter.",
+			param.NoteSeeParam(paramNameServe, paramNameErrorsAsJSON))
+
+		ps.AddNote(noteNameCoprocess,
+			"if you pass the program the '"+paramNameCoprocess+"'"+
+				" parameter then it will read requests from the"+
+				" standard input, one JSON object per line,"+
+				" and write a JSON response for each to the"+
+				" standard output. This avoids the cost of starting"+
+				" the program for each value to be converted."+
+				"\n\n"+
+				"A request has the following fields, all optional:"+
+				"\n\n"+
+				"id - any JSON value, returned unchanged in the response"+
+				"\n"+
+				"op - one of '"+opConvert+"' (the default),"+
+				" '"+opNearest+"', '"+opFamilies+"' or '"+opUnits+"'"+
+				"\n"+
+				"family - the family of units"+
+				"\n"+
+				"from - the unit to convert from"+
+				"\n"+
+				"to - a list of the units to convert to"+
+				"\n"+
+				"value - the value to convert (default 1)"+
+				"\n"+
+				"roughly, veryRoughly - round the converted values"+
+				"\n"+
+				"count - how many 'nearest' values to show"+
+				"\n"+
+				"ignoreTags - a list of unit tags to ignore"+
+				" when finding the 'nearest' values"+
+				"\n\n"+
+				"The response has the request id and either a 'result'"+
+				" or an 'error'. The result is as returned by the"+
+				" corresponding endpoint of the HTTP service and the"+
+				" error is as reported with the"+
+				" '"+paramNameErrorsAsJSON+"' parameter."+
+				"\n\n"+
+				"For example:"+
+				"\n\n"+
+				`{"id": 1, "from": "chain", "to": ["m"], "value": 80}`,
+			param.NoteSeeParam(paramNameCoprocess),
+			param.NoteSeeNote(noteNameServe))
+
+		ps.AddNote(noteNamePresets,
+			"a preset is a named collection of parameter settings,"+
+				" chosen with the '"+paramNamePreset+"' parameter."+
+				" The presets are read from the preset file which,"+
+				" by default, is:"+
+				"\n\n"+
+				dfltPresetFile()+
+				"\n\n"+
+				"Each preset starts with its name in square brackets"+
+				" and is followed by its parameter settings, one per"+
+				" line, given as in a configuration file:"+
+				" the parameter name, then, if the parameter takes a"+
+				" value, an '=' and the value. Blank lines and"+
+				" anything after a '#' are ignored."+
+				" The presets are checked when they are read;"+
+				" a preset cannot give a parameter more than once"+
+				" nor give parameters that conflict, such as"+
+				" '"+paramNameTo+"' and '"+paramNameNearest+"'."+
+				"\n\n"+
+				"For example:"+
+				"\n\n"+
+				"[carpentry]\n"+
+				paramNameFamily+"=length\n"+
+				paramNameTo+"=foot,inch\n"+
+				paramNameFractionDenominator+"=16\n"+
+				"\n"+
+				"Settings which you want to use every time the program"+
+				" is run, rather than as part of a preset, can be"+
+				" given in the program's configuration file:"+
+				"\n\n"+
+				configFileName(),
+			param.NoteSeeParam(paramNamePreset,
+				paramNamePresetFile, paramNameListPresets))
+
+		ps.AddNote(noteNameExitStatus, exitStatusNoteText(),
+			param.NoteSeeParam(paramNameErrorsAsJSON))
+
 		return nil
 	}
 }
+
+// exitStatusNoteText returns the text of the note describing the exit
+// statuses and error categories.
+func exitStatusNoteText() string {
+	text := "The program will exit with one of the following statuses." +
+		" The error category (as reported with the '" +
+		paramNameErrorsAsJSON + "' parameter) is given" +
+		" in brackets." +
+		"\n\n" +
+		fmt.Sprintf("%d: success", esOK)
+
+	cats := slices.SortedFunc(maps.Keys(errCategories),
+		func(a, b convert.Category) int {
+			return cmp.Compare(
+				errCategories[a].exitStatus, errCategories[b].exitStatus)
+		})
+
+	for _, c := range cats {
+		text += fmt.Sprintf("\n%d: %s (%s)",
+			errCategories[c].exitStatus, errCategories[c].desc, c)
+	}
+
+	return text
+}
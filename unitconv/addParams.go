@@ -2,19 +2,24 @@ package main
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/nickwells/check.mod/v2/check"
-	"github.com/nickwells/english.mod/english"
+	"github.com/nickwells/filecheck.mod/filecheck"
+	"github.com/nickwells/location.mod/location"
 	"github.com/nickwells/param.mod/v7/paction"
 	"github.com/nickwells/param.mod/v7/param"
 	"github.com/nickwells/param.mod/v7/psetter"
-	"github.com/nickwells/units.mod/v2/units"
 	"github.com/nickwells/unitsetter.mod/v4/unitsetter"
+	"github.com/nickwells/unittools/convert"
 )
 
 const (
-	paramNameFrom = "from"
-	paramNameTo   = "to"
+	paramNameFrom     = "from"
+	paramNameTo       = "to"
+	paramNameToSystem = "to-system"
+	paramNameCompound = "compound"
+	paramNameDelta    = "delta"
 
 	paramNameNearest          = "nearest"
 	paramNameNearestCount     = "nearest-count"
@@ -26,9 +31,23 @@ const (
 
 	paramNameFamily    = "family"
 	paramNameValue     = "value"
+	paramNameRange     = "range"
 	paramNameJustValue = "just-value"
 	paramNameWidth     = "width"
 	paramNamePrecision = "precision"
+	paramNameUnitStyle = "unit-style"
+
+	paramNameFractions           = "fractions"
+	paramNameFractionDenominator = "fraction-denominator"
+
+	paramNameErrorsAsJSON = "errors-as-json"
+
+	paramNameServe     = "serve"
+	paramNameCoprocess = "coprocess"
+
+	paramNamePreset      = "preset"
+	paramNamePresetFile  = "preset-file"
+	paramNameListPresets = "list-presets"
 )
 
 const (
@@ -49,25 +68,96 @@ func addParams(prog *prog) func(ps *param.PSet) error {
 
 		tOBCAF := toOrBestCounter.MakeActionFunc()
 
-		ps.Add(paramNameFrom,
+		fromParam := ps.Add(paramNameFrom,
 			psetter.String[string]{Value: &prog.unitFromName},
 			"The units the value is in."+
 				" It must be in the same family of units"+
 				" as the '"+paramNameTo+"' units."+
+				" The unit may be given by its name or plural name,"+
+				" in any case so long as that is not ambiguous,"+
+				" and with either spelling of metre or litre."+
+				" If the unit cannot be found, similar unit names"+
+				" will be suggested."+
 				"\n\n"+
 				familyChoice,
 			param.ValueName("unit-name"),
-			param.Attrs(param.MustBeSet),
 			param.SeeAlso(paramNameFamily, paramNameTo, paramNameNearest),
 		)
 
-		ps.Add(paramNameNearest,
-			psetter.Bool{Value: &prog.nearestVal},
+		toSystemSetter := psetter.Enum[convert.System]{
+			Value: &prog.toSystem,
+			AllowedVals: psetter.AllowedVals[convert.System]{
+				convert.SysMetric:   "the metric system",
+				convert.SysImperial: "the British imperial system",
+				convert.SysUSCustomary: "the United States" +
+					" customary system",
+				convert.SysSI: "the SI (Système Internationale) system",
+			},
+			Aliases: psetter.Aliases[convert.System]{
+				"us":  {convert.SysUSCustomary},
+				"SI":  {convert.SysSI},
+				"imp": {convert.SysImperial},
+			},
+			AllowInvalidInitialValue: true,
+		}
+
+		prog.addPresetParam(ps, toSystemSetter, []param.ActionFunc{tOBCAF},
+			paramNameToSystem,
+			"Convert the value into the most natural unit"+
+				" in the given system of measurement."+
+				" The units in the system are identified"+
+				" by their unit tags and the unit chosen is"+
+				" the one giving the nearest value, as for the '"+
+				paramNameNearest+"' parameter, among those"+
+				" in which the value is at least one but less"+
+				" than a thousand."+
+				" Historic, colloquial, specialist and rarely used"+
+				" units are never chosen and for the metric and SI"+
+				" systems only units which are a power of 1000 times"+
+				" the base unit are considered.",
+			param.ValueName("system"),
+			param.SeeAlso(
+				paramNameTo,
+				paramNameCompound,
+				paramNameNearestIgnoreTag,
+			),
+		)
+
+		prog.addPresetParam(ps,
+			psetter.Bool{Value: &prog.compound}, nil,
+			paramNameCompound,
+			"show the value in the compound form conventional"+
+				" for the system of measurement given with the '"+
+				paramNameToSystem+"' parameter."+
+				" For instance, a length in the imperial system"+
+				" will be shown in feet and inches.",
+			param.SeeAlso(paramNameToSystem),
+		)
+
+		prog.addPresetParam(ps, psetter.Bool{Value: &prog.delta}, nil,
+			paramNameDelta,
+			"convert the value as a difference between two values"+
+				" rather than as an absolute value."+
+				" This only matters for units, such as degrees"+
+				" Fahrenheit, where the conversion adds an offset"+
+				" as well as multiplying by a factor. With this"+
+				" parameter only the factor is applied so a difference"+
+				" of 10 degrees Celsius is shown as 18 degrees"+
+				" Fahrenheit rather than 50."+
+				"\n\n"+
+				"Without this parameter, a warning is given if a"+
+				" value given with the '"+paramNameValue+"'"+
+				" parameter is converted between such units.",
+			param.AltNames("difference", "interval"),
+		)
+
+		prog.addPresetParam(ps,
+			psetter.Bool{Value: &prog.nearestVal}, []param.ActionFunc{tOBCAF},
+			paramNameNearest,
 			"Convert the value into some unit in the same family of"+
 				" units such that the quantity in that unit is some small,"+
 				" preferably whole number value. A range of alternatives will"+
 				" be shown.",
-			param.PostAction(tOBCAF),
 			param.SeeAlso(
 				paramNameTo,
 				paramNameNearestCount,
@@ -76,13 +166,14 @@ func addParams(prog *prog) func(ps *param.PSet) error {
 			),
 		)
 
-		nearestCountParam := ps.Add(paramNameNearestCount,
+		prog.addPresetParam(ps,
 			psetter.Int[int]{
 				Value: &prog.nearestCount,
 				Checks: []check.ValCk[int]{
 					check.ValGE(1),
 				},
-			},
+			}, nil,
+			paramNameNearestCount,
 			"how many 'nearest' values should be shown.",
 			param.SeeAlso(
 				paramNameNearest,
@@ -91,13 +182,14 @@ func addParams(prog *prog) func(ps *param.PSet) error {
 			),
 		)
 
-		nearestPrecisionParam := ps.Add(paramNameNearestPrecision,
+		prog.addPresetParam(ps,
 			psetter.Float[float64]{
 				Value: &prog.nearestPrecision,
 				Checks: []check.ValCk[float64]{
 					check.ValGT(0.0),
 				},
-			},
+			}, nil,
+			paramNameNearestPrecision,
 			"when generating the 'nearest' value,"+
 				" how close to a whole number value to we allow"+
 				" when comparing converted values.",
@@ -108,20 +200,24 @@ func addParams(prog *prog) func(ps *param.PSet) error {
 			),
 		)
 
-		nearestIgnoreTagsParam := ps.Add(paramNameNearestIgnoreTag,
+		prog.addPresetParam(ps,
 			unitsetter.TagListAppender{
 				Value: &prog.nearestIgnoreTags,
-			},
-			"when generating the 'nearest' value,"+
+			}, nil,
+			paramNameNearestIgnoreTag,
+			"when generating the 'nearest' value"+
+				" or choosing a unit in the '"+paramNameToSystem+"'"+
+				" system of measurement,"+
 				" ignore any units with these tags.",
 			param.SeeAlso(
 				paramNameNearest,
 				paramNameNearestCount,
 				paramNameNearestPrecision,
+				paramNameToSystem,
 			),
 		)
 
-		ps.Add(paramNameTo,
+		prog.addPresetParam(ps,
 			psetter.StrList[string]{
 				Value: &prog.unitToNames,
 				Checks: []check.ValCk[[]string]{
@@ -129,39 +225,83 @@ func addParams(prog *prog) func(ps *param.PSet) error {
 					check.SliceAll[[]string](
 						check.StringLength[string](check.ValGT(0))),
 				},
-			},
+			}, []param.ActionFunc{tOBCAF},
+			paramNameTo,
 			"The units to convert the value into."+
 				" They must all be in the same family of units as"+
 				" the '"+paramNameFrom+"' unit."+
 				"\n\n"+
 				familyChoice,
 			param.ValueName("unit-name,..."),
-			param.PostAction(tOBCAF),
 			param.SeeAlso(paramNameFamily, paramNameFrom, paramNameNearest),
 		)
 
-		ps.Add(paramNameFamily,
+		prog.addPresetParam(ps,
 			unitsetter.FamilySetter{
 				Value: &prog.unitFamily,
-			},
+			}, nil,
+			paramNameFamily,
 			"the family of units to use."+
 				" The 'to' and 'from' units will be selected from this family.",
 			param.AltNames("f", "fam"),
 			param.SeeAlso(paramNameTo, paramNameFrom),
 		)
 
-		ps.Add(paramNameValue, psetter.Float[float64]{Value: &prog.val},
-			"the value to be converted.",
+		valueParam := ps.Add(paramNameValue,
+			psetter.String[string]{Value: &prog.valStr},
+			"the value to be converted."+
+				" If this is not a valid number the program will"+
+				" report a '"+string(convert.CatBadValue)+"' error."+
+				"\n\n"+
+				"A range of values can be given as two numbers"+
+				" separated by '"+convert.RangeSep+"', for instance,"+
+				" 32"+convert.RangeSep+"104. Both ends of the"+
+				" range are converted and the result is shown as"+
+				" a range. Any units which depend on the value,"+
+				" such as the nearest units, are chosen using the"+
+				" end of the range furthest from zero. If the"+
+				" value is to be shown in several units, as a"+
+				" compound value, each end of the range is shown"+
+				" in this way.",
 			param.AltNames("v", "val"),
+			param.ValueName("number"),
+			param.PostAction(paction.SetVal(&prog.valGiven, true)),
+			param.SeeAlso(paramNameRange),
 		)
 
-		ps.Add(paramNameWidth, psetter.Int[int]{Value: &prog.displayWidth},
+		var rangeVals []string
+
+		rangeParam := ps.Add(paramNameRange,
+			psetter.StrList[string]{
+				Value: &rangeVals,
+				Checks: []check.ValCk[[]string]{
+					check.SliceLength[[]string](check.ValEQ(2)),
+				},
+			},
+			"the range of values to be converted, given as"+
+				" the two ends of the range. This is an alternative"+
+				" to giving the range as the value.",
+			param.PostAction(
+				func(_ location.L, _ *param.BaseParam, _ []string) error {
+					prog.valStr = strings.Join(rangeVals, convert.RangeSep)
+
+					return nil
+				}),
+			param.ValueName("lo,hi"),
+			param.SeeAlso(paramNameValue),
+		)
+
+		prog.addPresetParam(ps,
+			psetter.Int[int]{Value: &prog.displayWidth}, nil,
+			paramNameWidth,
 			"the space to allow for the display of the"+
 				" converted value (the number part).",
 			param.SeeAlso(paramNamePrecision),
 		)
 
-		ps.Add(paramNamePrecision, psetter.Int[int]{Value: &prog.displayPrec},
+		prog.addPresetParam(ps,
+			psetter.Int[int]{Value: &prog.displayPrec}, nil,
+			paramNamePrecision,
 			"the number of digits of precision to allow"+
 				" when displaying the"+
 				" converted value (the number part).",
@@ -169,7 +309,68 @@ func addParams(prog *prog) func(ps *param.PSet) error {
 			param.SeeAlso(paramNameWidth),
 		)
 
-		ps.Add(paramNameJustValue, psetter.Bool{Value: &prog.justVal},
+		prog.addPresetParam(ps,
+			psetter.Enum[unitStyle]{
+				Value: &prog.unitStyle,
+				AllowedVals: psetter.AllowedVals[unitStyle]{
+					unitStyleName: "the name of the unit, in the singular" +
+						" if the value as shown is one and in the" +
+						" plural otherwise",
+					unitStyleAbbrev: "the abbreviated form of the unit",
+					unitStyleID:     "the name by which the unit is known",
+				},
+			}, nil,
+			paramNameUnitStyle,
+			"how the units are shown alongside the values."+
+				" The choice of singular or plural name is made"+
+				" after the value has been rounded to the"+
+				" display precision so a value shown as"+
+				" 1.000 will be followed by the singular name.",
+			param.ValueName("style"),
+			param.SeeAlso(paramNamePrecision, paramNameJustValue),
+		)
+
+		prog.addPresetParam(ps, psetter.Bool{Value: &prog.fractions}, nil,
+			paramNameFractions,
+			"show the converted values as mixed fractions, such as"+
+				" 3 5/16, rather than as decimals."+
+				" The denominator is the smallest power of two"+
+				fmt.Sprintf(" up to %d", convert.MaxAutoDenominator)+
+				" which gives the value to the display precision"+
+				fmt.Sprintf(" or else %d.", convert.MaxAutoDenominator)+
+				" If the fraction is not exactly the value,"+
+				" the difference between the value and the fraction"+
+				" is shown in brackets after the unit."+
+				"\n\n"+
+				"The value being converted is still shown as a decimal."+
+				" This cannot be given with the '"+paramNameNearest+
+				"' parameter.",
+			param.AltNames("fraction"),
+			param.SeeAlso(paramNameFractionDenominator, paramNamePrecision),
+		)
+
+		prog.addPresetParam(ps,
+			psetter.Int[int64]{
+				Value: &prog.fractionDenom,
+				Checks: []check.ValCk[int64]{
+					check.ValGE[int64](1),
+				},
+			}, []param.ActionFunc{paction.SetVal(&prog.fractions, true)},
+			paramNameFractionDenominator,
+			"show the converted values as mixed fractions using"+
+				" this denominator. The fraction is reduced to"+
+				" its lowest terms so, for instance, with a"+
+				" denominator of 16, eight sixteenths are"+
+				" shown as 1/2."+
+				" Setting this implies the '"+paramNameFractions+
+				"' parameter.",
+			param.AltNames("fraction-denom"),
+			param.ValueName("N"),
+			param.SeeAlso(paramNameFractions),
+		)
+
+		prog.addPresetParam(ps, psetter.Bool{Value: &prog.justVal}, nil,
+			paramNameJustValue,
 			"just show the result of the conversion and not"+
 				" the from and to units as well."+
 				" This flag will make the result easier to use in"+
@@ -177,88 +378,233 @@ func addParams(prog *prog) func(ps *param.PSet) error {
 			param.AltNames("just-val", "value-only", "val-only", "short", "s"),
 		)
 
-		ps.Add(paramNameRoughly, psetter.Nil{},
+		prog.addPresetParam(ps, psetter.Nil{},
+			[]param.ActionFunc{
+				paction.SetVal(&prog.roughly, true),
+				paction.SetVal(&prog.roughPrecision, roughPrecisionValue),
+			},
+			paramNameRoughly,
 			fmt.Sprintf("just show the result rounded to the nearest"+
 				" multiple of 10 or 5 within %d%% of the original value.",
 				roughPrecisionValue),
-			param.PostAction(paction.SetVal(&prog.roughly, true)),
-			param.PostAction(paction.SetVal(&prog.roughPrecision,
-				roughPrecisionValue)),
 			param.SeeAlso(paramNameVeryRoughly),
 		)
 
-		ps.Add(paramNameVeryRoughly, psetter.Nil{},
+		prog.addPresetParam(ps, psetter.Nil{},
+			[]param.ActionFunc{
+				paction.SetVal(&prog.roughly, true),
+				paction.SetVal(&prog.roughPrecision, veryRoughPrecisionValue),
+			},
+			paramNameVeryRoughly,
 			fmt.Sprintf("just show the result rounded to the nearest"+
 				" multiple of 10 or 5 within %d%% of the original value.",
 				veryRoughPrecisionValue),
-			param.PostAction(paction.SetVal(&prog.roughly, true)),
-			param.PostAction(paction.SetVal(&prog.roughPrecision,
-				veryRoughPrecisionValue)),
 			param.SeeAlso(paramNameRoughly),
 		)
 
+		ps.Add(paramNameErrorsAsJSON, psetter.Bool{Value: &prog.errorsAsJSON},
+			"report any errors found while converting the value"+
+				" as JSON records on the standard error, one per line."+
+				" Each record gives the error category,"+
+				" the exit status, the message and, where relevant,"+
+				" the unit name, the families of units involved"+
+				" and any suggested alternative unit names."+
+				" Warnings are reported in the same way, with a"+
+				" category of '"+string(catWarning)+"'"+
+				" and no exit status."+
+				"\n\n"+
+				"Errors in the parameters themselves are also"+
+				" reported in this way, with a category of '"+
+				string(catBadParams)+"'.",
+			param.AltNames("json-errors"),
+			param.SeeAlso(paramNameJustValue),
+			param.SeeNote(noteNameExitStatus),
+		)
+
+		ps.Add(paramNameServe,
+			psetter.String[string]{
+				Value: &prog.serveAddr,
+				Checks: []check.ValCk[string]{
+					check.StringLength[string](check.ValGT(0)),
+				},
+			},
+			"run the program as an HTTP service listening on the"+
+				" given address. Conversions are requested through"+
+				" the service's endpoints and the results"+
+				" are returned as JSON."+
+				" The '"+paramNameWidth+"' and '"+paramNamePrecision+"'"+
+				" parameters are used when formatting the"+
+				" converted values."+
+				"\n\n"+
+				"This cannot be given with the '"+paramNameFrom+"',"+
+				" '"+paramNameTo+"', '"+paramNameNearest+"'"+
+				" or '"+paramNameToSystem+"' parameters.",
+			param.ValueName("[host]:port"),
+			param.SeeAlso(paramNameCoprocess),
+			param.SeeNote(noteNameServe),
+		)
+
+		ps.Add(paramNameCoprocess, psetter.Bool{Value: &prog.coprocess},
+			"run the program as a co-process. Requests are read"+
+				" from the standard input, one JSON object per line,"+
+				" and a response is written for each request"+
+				" to the standard output, again one JSON object per line."+
+				" A malformed request gets an error response and"+
+				" the program carries on reading requests until the"+
+				" end of the input."+
+				" The '"+paramNameWidth+"' and '"+paramNamePrecision+"'"+
+				" parameters are used when formatting the"+
+				" converted values."+
+				"\n\n"+
+				"This cannot be given with the '"+paramNameFrom+"',"+
+				" '"+paramNameTo+"', '"+paramNameNearest+"',"+
+				" '"+paramNameToSystem+"' or '"+paramNameServe+"'"+
+				" parameters.",
+			param.AltNames("co-process"),
+			param.SeeAlso(paramNameServe),
+			param.SeeNote(noteNameCoprocess),
+		)
+
+		ps.Add(paramNamePreset,
+			psetter.String[string]{
+				Value: &prog.presetName,
+				Checks: []check.ValCk[string]{
+					check.StringLength[string](check.ValGT(0)),
+				},
+			},
+			"use the settings in the named preset."+
+				" A preset is a named collection of parameter settings"+
+				" read from the preset file. Any parameter given on"+
+				" the command line overrides the setting in the preset"+
+				" of that parameter and of any parameter which conflicts"+
+				" with it, so, for instance, giving the"+
+				" '"+paramNameTo+"' parameter overrides a preset"+
+				" setting of '"+paramNameNearest+"'.",
+			param.ValueName("name"),
+			param.SeeAlso(paramNamePresetFile, paramNameListPresets),
+			param.SeeNote(noteNamePresets),
+		)
+
+		ps.Add(paramNamePresetFile,
+			psetter.Pathname{
+				Value:       &prog.presetFile,
+				Expectation: filecheck.FileExists(),
+			},
+			"the file from which the presets are read.",
+			param.ValueName("file"),
+			param.SeeAlso(paramNamePreset, paramNameListPresets),
+			param.SeeNote(noteNamePresets),
+		)
+
+		ps.Add(paramNameListPresets, psetter.Bool{Value: &prog.listPresets},
+			"show the available presets and their settings and exit.",
+			param.AltNames("presets"),
+			param.SeeAlso(paramNamePreset, paramNamePresetFile),
+			param.SeeNote(noteNamePresets),
+		)
+
 		ps.AddFinalCheck(func() error {
+			return prog.usePreset(ps)
+		})
+
+		ps.AddFinalCheck(func() error {
+			if prog.listPresets {
+				return nil
+			}
+
+			if prog.serveAddr != "" && prog.coprocess {
+				return fmt.Errorf(
+					"the %q and %q parameters cannot both be given",
+					paramNameServe, paramNameCoprocess)
+			}
+
+			for _, mode := range []struct {
+				name  string
+				isSet bool
+			}{
+				{paramNameServe, prog.serveAddr != ""},
+				{paramNameCoprocess, prog.coprocess},
+			} {
+				if !mode.isSet {
+					continue
+				}
+
+				if fromParam.HasBeenSet() || toOrBestCounter.Count() != 0 {
+					return fmt.Errorf(
+						"the %q parameter cannot be given with"+
+							" the %q, %q, %q or %q parameters",
+						mode.name,
+						paramNameFrom, paramNameTo,
+						paramNameNearest, paramNameToSystem)
+				}
+
+				return nil
+			}
+
+			if !fromParam.HasBeenSet() {
+				return fmt.Errorf(
+					"the %q parameter must be given (unless the %q"+
+						" or %q parameter is given)",
+					paramNameFrom, paramNameServe, paramNameCoprocess)
+			}
+
 			if toOrBestCounter.Count() != 1 {
 				return fmt.Errorf(
-					"you must give either %q or %q (not both)",
-					paramNameTo, paramNameNearest)
+					"you must give exactly one of %q, %q or %q",
+					paramNameTo, paramNameNearest, paramNameToSystem)
 			}
 
-			if prog.nearestVal {
-				return prog.findNearestVals()
+			if valueParam.HasBeenSet() && rangeParam.HasBeenSet() {
+				return fmt.Errorf(
+					"the %q and %q parameters cannot both be given",
+					paramNameValue, paramNameRange)
 			}
 
-			if !prog.nearestVal &&
-				(nearestCountParam.HasBeenSet() ||
-					nearestPrecisionParam.HasBeenSet() ||
-					nearestIgnoreTagsParam.HasBeenSet()) {
+			if prog.setOnCommandLine(paramNameCompound) &&
+				prog.toSystem == "" {
 				return fmt.Errorf(
 					"unless the %q parameter is given"+
-						" the %q, %q or %q parameters have no effect",
-					paramNameNearest,
-					paramNameNearestCount,
-					paramNameNearestPrecision,
-					paramNameNearestIgnoreTag)
+						" the %q parameter has no effect",
+					paramNameToSystem, paramNameCompound)
 			}
 
-			if prog.unitFamily != nil {
-				return populateTargetUnitsFromFamily(prog)
+			if prog.fractions && prog.nearestVal {
+				return fmt.Errorf(
+					"the %q and %q parameters cannot both be given",
+					paramNameFractions, paramNameNearest)
 			}
 
-			for _, f := range units.GetFamilies() {
-				prog.unitFamily = f
-				if err := populateTargetUnitsFromFamily(prog); err == nil {
-					return nil
+			if prog.delta && prog.nearestVal {
+				return fmt.Errorf(
+					"the %q and %q parameters cannot both be given",
+					paramNameDelta, paramNameNearest)
+			}
+
+			if prog.setOnCommandLine(
+				paramNameNearestCount, paramNameNearestPrecision) {
+				if !prog.nearestVal {
+					return fmt.Errorf(
+						"unless the %q parameter is given"+
+							" the %q or %q parameters have no effect",
+						paramNameNearest,
+						paramNameNearestCount,
+						paramNameNearestPrecision)
 				}
 			}
 
-			return fmt.Errorf("there is no unit-family having both %q and %s",
-				prog.unitFromName,
-				english.JoinQuoted(prog.unitToNames, ", ", " and "))
+			if prog.setOnCommandLine(paramNameNearestIgnoreTag) &&
+				!prog.nearestVal && prog.toSystem == "" {
+				return fmt.Errorf(
+					"unless the %q or %q parameter is given"+
+						" the %q parameter has no effect",
+					paramNameNearest,
+					paramNameToSystem,
+					paramNameNearestIgnoreTag)
+			}
+
+			return nil
 		})
 
 		return nil
 	}
 }
-
-// populateTargetUnitsFromFamily finds the units in the supplied family
-func populateTargetUnitsFromFamily(prog *prog) error {
-	var err error
-
-	prog.unitFrom, err = prog.unitFamily.GetUnit(prog.unitFromName)
-	if err != nil {
-		return err
-	}
-
-	prog.unitTo = []units.Unit{}
-	for _, unitName := range prog.unitToNames {
-		u, err := prog.unitFamily.GetUnit(unitName)
-		if err != nil {
-			return err
-		}
-
-		prog.unitTo = append(prog.unitTo, u)
-	}
-
-	return nil
-}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"path/filepath"
+
+	"github.com/nickwells/filecheck.mod/filecheck"
+	"github.com/nickwells/param.mod/v7/param"
+	"github.com/nickwells/xdg.mod/xdg"
+)
+
+// configDir returns the directory holding the configuration files for
+// this program
+func configDir() string {
+	return filepath.Join(xdg.ConfigHome(),
+		"github.com", "nickwells", "unittools", "unitconv")
+}
+
+// configFileName returns the name of the program's configuration file.
+// This can hold the per-user default parameter settings.
+func configFileName() string {
+	return filepath.Join(configDir(), "common.cfg")
+}
+
+// dfltPresetFile returns the default name of the file holding the presets
+func dfltPresetFile() string {
+	return filepath.Join(configDir(), "presets.cfg")
+}
+
+// setConfigFile sets the program's configuration file. Parameters given
+// there are set before those given on the command line.
+func setConfigFile(ps *param.PSet) error {
+	ps.SetConfigFileStrict(configFileName(), filecheck.Optional)
+
+	return nil
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/nickwells/unittools/convert"
+)
+
+// These are the operations that a co-process request can ask for
+const (
+	opConvert  = "convert"
+	opNearest  = "nearest"
+	opFamilies = "families"
+	opUnits    = "units"
+)
+
+// maxRequestLen is the length of the longest request line that will be read
+const maxRequestLen = 1024 * 1024
+
+// coprocRequest is a single request read by the co-process. The ID is
+// optional and is returned unchanged in the response so that the caller can
+// match responses to requests.
+type coprocRequest struct {
+	ID          json.RawMessage `json:"id,omitempty"`
+	Op          string          `json:"op"`
+	Family      string          `json:"family"`
+	From        string          `json:"from"`
+	To          []string        `json:"to"`
+	Value       *float64        `json:"value"`
+	Range       []float64       `json:"range"`
+	Roughly     bool            `json:"roughly"`
+	VeryRoughly bool            `json:"veryRoughly"`
+	Delta       bool            `json:"delta"`
+	Count       int             `json:"count"`
+	IgnoreTags  []string        `json:"ignoreTags"`
+}
+
+// coprocResponse is the response written by the co-process for each
+// request. Exactly one of Result or Error will be set.
+type coprocResponse struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Result any             `json:"result,omitempty"`
+	Error  *errorRecord    `json:"error,omitempty"`
+}
+
+// runCoprocess runs the program as a co-process, reading one JSON request per
+// line from the standard input and writing one JSON response per line to
+// the standard output. Malformed requests, including lines longer than
+// maxRequestLen, are reported in the response and do not stop the
+// co-process.
+func (prog *prog) runCoprocess() {
+	enc := json.NewEncoder(prog.stdout)
+	r := bufio.NewReaderSize(prog.stdin, maxRequestLen)
+
+	for {
+		line, tooLong, err := readRequest(r)
+		if err != nil && !errors.Is(err, io.EOF) {
+			prog.reportError(convert.NewError(catServeFailure,
+				"cannot read the requests: %s", err))
+
+			return
+		}
+
+		var resp coprocResponse
+
+		line = bytes.TrimSpace(line)
+
+		switch {
+		case tooLong:
+			resp = makeCoprocErrResponse(nil,
+				convert.NewError(catBadRequest,
+					"the request is longer than the maximum of %d bytes",
+					maxRequestLen))
+		case len(line) == 0:
+			if err != nil {
+				return
+			}
+
+			continue
+		default:
+			resp = prog.coprocAnswer(line)
+		}
+
+		if err := enc.Encode(resp); err != nil {
+			prog.reportError(convert.NewError(catServeFailure,
+				"cannot write the response: %s", err))
+
+			return
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+// readRequest reads the next line from the reader. If the line does not
+// fit in the reader's buffer the rest of it is read and discarded and
+// tooLong is returned as true. The error is io.EOF if the input ended
+// after the line was read; the line may still hold a request.
+func readRequest(r *bufio.Reader) (line []byte, tooLong bool, err error) {
+	line, err = r.ReadSlice('\n')
+	if !errors.Is(err, bufio.ErrBufferFull) {
+		return line, false, err
+	}
+
+	for errors.Is(err, bufio.ErrBufferFull) {
+		_, err = r.ReadSlice('\n')
+	}
+
+	return nil, true, err
+}
+
+// coprocAnswer parses the request line and returns the response
+func (prog *prog) coprocAnswer(line []byte) coprocResponse {
+	var req coprocRequest
+
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(&req); err != nil {
+		// try to recover the ID so the caller can still match the response
+		var idOnly struct {
+			ID json.RawMessage `json:"id"`
+		}
+
+		_ = json.Unmarshal(line, &idOnly)
+
+		return makeCoprocErrResponse(idOnly.ID,
+			convert.NewError(catBadRequest, "malformed request: %s", err))
+	}
+
+	res, err := prog.coprocDo(req)
+	if err != nil {
+		return makeCoprocErrResponse(req.ID, err)
+	}
+
+	return coprocResponse{ID: req.ID, Result: res}
+}
+
+// makeCoprocErrResponse returns a response reporting the error
+func makeCoprocErrResponse(id json.RawMessage, err error) coprocResponse {
+	rec := makeErrorRecord(err)
+	rec.ExitStatus = 0
+
+	return coprocResponse{ID: id, Error: &rec}
+}
+
+// coprocDo performs the operation given in the request
+func (prog *prog) coprocDo(req coprocRequest) (any, error) {
+	val := 1.0
+	if req.Value != nil {
+		val = *req.Value
+	}
+
+	switch req.Op {
+	case opConvert, "":
+		if req.Range != nil {
+			return prog.coprocConvertRange(req)
+		}
+
+		return prog.doConvert(convert.Request{
+			Family:         req.Family,
+			From:           req.From,
+			To:             req.To,
+			Value:          val,
+			RoughPrecision: roughPrecision(req.Roughly, req.VeryRoughly),
+			Delta:          req.Delta,
+		})
+	case opNearest:
+		cfg, err := prog.makeNearestCfg(req.Count, req.IgnoreTags)
+		if err != nil {
+			return nil, err
+		}
+
+		return prog.doNearest(nearestRequest{
+			Family:         req.Family,
+			From:           req.From,
+			Value:          val,
+			RoughPrecision: roughPrecision(req.Roughly, req.VeryRoughly),
+			Cfg:            cfg,
+		})
+	case opFamilies:
+		return familyList(), nil
+	case opUnits:
+		return unitList(req.Family)
+	}
+
+	return nil, convert.NewError(catBadRequest,
+		"unknown operation: %q (it should be one of %q, %q, %q or %q)",
+		req.Op, opConvert, opNearest, opFamilies, opUnits)
+}
+
+// coprocConvertRange converts the range of values given in the request
+func (prog *prog) coprocConvertRange(req coprocRequest) (any, error) {
+	if req.Value != nil {
+		return nil, convert.NewError(catBadRequest,
+			"a request cannot give both a value and a range")
+	}
+
+	if len(req.Range) != 2 { //nolint:mnd
+		return nil, convert.NewError(catBadRequest,
+			"a range must have exactly two values (%d were given)",
+			len(req.Range))
+	}
+
+	return prog.doConvertRange(rangeRequest{
+		Family:         req.Family,
+		From:           req.From,
+		To:             req.To,
+		Range:          convert.MakeRange(req.Range[0], req.Range[1]),
+		RoughPrecision: roughPrecision(req.Roughly, req.VeryRoughly),
+		Delta:          req.Delta,
+	})
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+const coprocSubDir = "coprocess"
+
+var coprocGFC = testhelper.GoldenFileCfg{
+	DirNames:               []string{testDataDir, coprocSubDir},
+	Sfx:                    "ndjson",
+	UpdFlagName:            "upd-coproc-gf",
+	KeepBadResultsFlagName: "keep-bad-coproc-results",
+}
+
+func init() {
+	coprocGFC.AddUpdateFlag()
+	coprocGFC.AddKeepBadResultsFlag()
+}
+
+func TestCoprocess(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		input []string
+	}{
+		{
+			ID: testhelper.MkID("convert"),
+			input: []string{
+				`{"id": 1, "from": "chain", "to": ["m"], "value": 80}`,
+				`{"id": "two", "op": "convert", "from": "F", "to": ["C"],` +
+					` "value": 98.6}`,
+				`{"id": 3, "family": "length", "from": "m",` +
+					` "to": ["foot", "inch"], "value": 1.8}`,
+				`{"id": 4, "from": "chain", "to": ["m"], "value": 80,` +
+					` "roughly": true}`,
+			},
+		},
+		{
+			ID: testhelper.MkID("convert-delta"),
+			input: []string{
+				`{"id": 1, "from": "C", "to": ["F"], "value": 10,` +
+					` "delta": true}`,
+				`{"id": 2, "from": "C", "to": ["F"], "range": [10, 20],` +
+					` "delta": true}`,
+			},
+		},
+		{
+			ID: testhelper.MkID("convert-range"),
+			input: []string{
+				`{"id": 1, "from": "F", "to": ["C"], "range": [104, 32]}`,
+				`{"id": 2, "from": "F", "to": ["C", "K"], "range": [32, 104]}`,
+				`{"id": 3, "from": "F", "to": ["C"], "range": [32],` +
+					` "value": 1}`,
+			},
+		},
+		{
+			ID: testhelper.MkID("nearest"),
+			input: []string{
+				`{"id": 1, "op": "nearest", "family": "length", "from": "m",` +
+					` "value": 10.05534, "count": 2}`,
+			},
+		},
+		{
+			ID: testhelper.MkID("units"),
+			input: []string{
+				`{"id": 1, "op": "units", "family": "angle"}`,
+			},
+		},
+		{
+			ID: testhelper.MkID("errors-do-not-stop"),
+			input: []string{
+				`{"id": 1, "from": "chian", "to": ["m"]}`,
+				`this is not JSON`,
+				`{"id": 3, "from": "chain", "to": ["m"], "value": "eighty"}`,
+				``,
+				`{"id": 4, "from": "chain", "to": ["kg"]}`,
+				`{"id": 5, "op": "nonesuch"}`,
+				`{"id": 6, "from": "minute", "op": "nearest"}`,
+				`{"id": 7, "from": "chain", "to": ["m"], "unknown": true}`,
+				`{"id": 8, "from": "chain"}`,
+				`{"id": 9, "from": "chain", "to": ["m"], "value": 80}`,
+			},
+		},
+		{
+			ID: testhelper.MkID("over-long-line"),
+			input: []string{
+				`{"id": 1, "from": "` +
+					strings.Repeat("x", maxRequestLen) + `"}`,
+				`{"id": 2, "from": "chain", "to": ["m"], "value": 80}`,
+				`{"id": 3, "from": "` +
+					strings.Repeat("x", maxRequestLen) + `"}`,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var stdoutBuf, stderrBuf bytes.Buffer
+
+			prog := newProg()
+			prog.stdin = strings.NewReader(strings.Join(tc.input, "\n"))
+			prog.stdout = &stdoutBuf
+			prog.stderr = &stderrBuf
+
+			prog.runCoprocess()
+
+			testhelper.DiffInt(t, tc.IDStr(), "exit status",
+				prog.exitStatus, esOK)
+			testhelper.DiffString(t, tc.IDStr(), "stderr",
+				stderrBuf.String(), "")
+
+			coprocGFC.Check(t, tc.IDStr(), tc.Name, stdoutBuf.Bytes())
+		})
+	}
+}
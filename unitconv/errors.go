@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/nickwells/unittools/convert"
+)
+
+// These are the exit statuses that the program can return. Note that any
+// errors in the parameters are reported when the parameters are parsed
+// and the program will exit with a status of 1 (esParamErrors).
+const (
+	esOK = iota
+	esParamErrors
+	esBadConversion
+	esUnknownUnit
+	esAmbiguousUnit
+	esFamilyMismatch
+	esBadValue
+	esPrecisionLoss
+	esPartialFailure
+	esServeFailure
+)
+
+// catBadParams is the category of errors found in the parameters
+const catBadParams convert.Category = "bad-params"
+
+// catServeFailure is the category of errors found when running the
+// program as an HTTP service or as a co-process
+const catServeFailure convert.Category = "serve-failure"
+
+// catWarning is the category of warnings. Warnings are reported in the
+// same way as errors but do not change the exit status.
+const catWarning convert.Category = "warning"
+
+// errCategories maps each error category to the exit status it causes and
+// a description used in the program notes
+var errCategories = map[convert.Category]struct {
+	exitStatus int
+	desc       string
+}{
+	catBadParams: {
+		esParamErrors,
+		"there were errors in the parameters",
+	},
+	convert.CatBadConversion: {
+		esBadConversion,
+		"the value could not be converted into the target units",
+	},
+	convert.CatUnknownUnit: {
+		esUnknownUnit,
+		"a unit name could not be found",
+	},
+	convert.CatAmbiguousUnit: {
+		esAmbiguousUnit,
+		"a unit name was found in more than one family of units",
+	},
+	convert.CatFamilyMismatch: {
+		esFamilyMismatch,
+		"the units are not all in the same family of units",
+	},
+	convert.CatBadValue: {
+		esBadValue,
+		"the value to be converted is not a valid number",
+	},
+	convert.CatPrecisionLoss: {
+		esPrecisionLoss,
+		"a converted value is too small to be shown" +
+			" with the requested precision",
+	},
+	convert.CatPartialFailure: {
+		esPartialFailure,
+		"some but not all of the conversions failed",
+	},
+	catServeFailure: {
+		esServeFailure,
+		"the HTTP service or the co-process could not be started" +
+			" or has failed",
+	},
+}
+
+// errorRecord is the structure written to the standard error when errors
+// are reported as JSON.
+type errorRecord struct {
+	Category    convert.Category `json:"category"`
+	ExitStatus  int              `json:"exitStatus,omitempty"`
+	Message     string           `json:"message"`
+	Unit        string           `json:"unit,omitempty"`
+	Families    []string         `json:"families,omitempty"`
+	Suggestions []string         `json:"suggestions,omitempty"`
+}
+
+// makeErrorRecord converts the error into an errorRecord. An error that has
+// no category is treated as a bad conversion.
+func makeErrorRecord(err error) errorRecord {
+	ce := convert.AsError(err)
+
+	return errorRecord{
+		Category:    ce.Category,
+		ExitStatus:  errCategories[ce.Category].exitStatus,
+		Message:     ce.Msg,
+		Unit:        ce.Unit,
+		Families:    ce.Families,
+		Suggestions: ce.Suggestions,
+	}
+}
+
+// reportError reports the error on the standard error, either as a plain
+// message or as a JSON record, and sets the exit status appropriately.
+func (prog *prog) reportError(err error) {
+	rec := makeErrorRecord(err)
+
+	prog.setExitStatus(rec.ExitStatus)
+
+	if prog.errorsAsJSON {
+		b, jsonErr := json.Marshal(rec)
+		if jsonErr == nil {
+			fmt.Fprintln(prog.stderr, string(b))
+			return
+		}
+	}
+
+	fmt.Fprintln(prog.stderr, "Error:", rec.Message)
+}
+
+// reportWarning reports the warning on the standard error, either as a
+// plain message or as a JSON record. The exit status is not changed.
+func (prog *prog) reportWarning(msg string) {
+	if prog.errorsAsJSON {
+		b, err := json.Marshal(errorRecord{Category: catWarning, Message: msg})
+		if err == nil {
+			fmt.Fprintln(prog.stderr, string(b))
+			return
+		}
+	}
+
+	fmt.Fprintln(prog.stderr, "Warning:", msg)
+}
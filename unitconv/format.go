@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/nickwells/units.mod/v2/units"
+	"github.com/nickwells/unittools/convert"
+)
+
+// unitStyle describes how the unit is shown alongside a value
+type unitStyle string
+
+// These are the available styles for showing the unit
+const (
+	unitStyleName   unitStyle = "name"
+	unitStyleAbbrev unitStyle = "abbrev"
+	unitStyleID     unitStyle = "id"
+)
+
+// formatNumber returns the number formatted with the display width and
+// precision. A value which would be shown as zero is shown without a sign.
+func (prog *prog) formatNumber(v float64) string {
+	if math.Abs(v) < prog.displayTolerance() {
+		v = 0
+	}
+
+	return fmt.Sprintf("%*.*f", prog.displayWidth, prog.displayPrec, v)
+}
+
+// unitText returns the text used to show the unit alongside the displayed
+// number. If the unit is shown by name the singular form is used if the
+// number, as displayed, is one (or minus one) and the plural form otherwise.
+func (prog *prog) unitText(u units.Unit, displayedAsOne bool) string {
+	switch prog.unitStyle {
+	case unitStyleAbbrev:
+		if abbrev := u.Abbrev(); abbrev != "" {
+			return abbrev
+		}
+
+		return u.ID()
+	case unitStyleID:
+		return u.ID()
+	}
+
+	name := u.NamePlural()
+	if displayedAsOne {
+		name = u.Name()
+	}
+
+	if isDistinctAlias(u) {
+		name += " (" + u.AliasName() + ")"
+	}
+
+	return name
+}
+
+// isDistinctAlias returns true if the unit was found through an alias which
+// is not just a form of its name
+func isDistinctAlias(u units.Unit) bool {
+	alias := u.AliasName()
+	if alias == "" {
+		return false
+	}
+
+	for _, name := range []string{u.Name(), u.NamePlural()} {
+		if alias == name || alias == strings.ReplaceAll(name, " ", "-") {
+			return false
+		}
+	}
+
+	return true
+}
+
+// shownNumber is a number as it is to be shown
+type shownNumber struct {
+	text string
+	// singular is set if the unit name should be given in the singular
+	singular bool
+	// residual is the difference between the number and a fraction
+	// showing it
+	residual float64
+}
+
+// showDecimal returns the number formatted as a decimal
+func (prog *prog) showDecimal(v float64) shownNumber {
+	text := prog.formatNumber(v)
+	displayed, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+
+	return shownNumber{
+		text:     text,
+		singular: err == nil && math.Abs(displayed) == 1,
+	}
+}
+
+// showResult returns the converted number formatted for display. If
+// fractions have been requested the number is shown as a mixed fraction. If
+// the number cannot be shown as a fraction the error is reported and the
+// number is shown as a decimal.
+func (prog *prog) showResult(v float64) shownNumber {
+	if !prog.fractions {
+		return prog.showDecimal(v)
+	}
+
+	var (
+		f   convert.Fraction
+		err error
+	)
+
+	if prog.fractionDenom > 0 {
+		f, err = convert.NearestFraction(v, prog.fractionDenom)
+	} else {
+		f, err = convert.AutoFraction(v, prog.displayTolerance())
+	}
+
+	if err != nil {
+		prog.reportError(err)
+
+		return prog.showDecimal(v)
+	}
+
+	return shownNumber{
+		text: fmt.Sprintf("%*s", prog.displayWidth, f.String()),
+		// a proper fraction reads better with the singular name: 5/16 inch
+		singular: (f.Whole == 0 && f.Numerator != 0) ||
+			(f.Whole == 1 && f.Numerator == 0),
+		residual: f.Residual,
+	}
+}
+
+// residualText returns the text showing the residual differences between
+// the numbers and the fractions showing them. It is empty if every
+// residual would be shown as zero.
+func (prog *prog) residualText(nums ...shownNumber) string {
+	shown := false
+	parts := []string{}
+
+	for _, n := range nums {
+		if math.Abs(n.residual) >= prog.displayTolerance() {
+			shown = true
+		}
+
+		parts = append(parts,
+			fmt.Sprintf("%+.*f", prog.displayPrec, n.residual))
+	}
+
+	if !shown {
+		return ""
+	}
+
+	return " (" + strings.Join(parts, convert.RangeSep) + ")"
+}
+
+// formatValUnit returns the ValUnit formatted for display. Unless just the
+// value is to be shown, the number is followed by the unit in the chosen
+// style.
+func (prog *prog) formatValUnit(v units.ValUnit) string {
+	n := prog.showDecimal(v.V)
+
+	if prog.justVal {
+		return n.text
+	}
+
+	return n.text + " " + prog.unitText(v.U, n.singular)
+}
+
+// formatResult returns the converted value formatted for display. If
+// fractions have been requested the value is shown as a mixed fraction
+// followed, unless just the value is to be shown, by the unit and then by
+// the residual difference between the value and the fraction, if this
+// would not be shown as zero.
+func (prog *prog) formatResult(v units.ValUnit) string {
+	n := prog.showResult(v.V)
+
+	if prog.justVal {
+		return n.text
+	}
+
+	return n.text + " " + prog.unitText(v.U, n.singular) +
+		prog.residualText(n)
+}
+
+// formatRange returns the range of values formatted for display. The two
+// ends of the range are separated by convert.RangeSep and, unless just
+// the values are to be shown, followed by the plural name of the unit. The
+// ends of a converted range are shown as for formatResult.
+func (prog *prog) formatRange(r convert.Range, u units.Unit,
+	isResult bool,
+) string {
+	show := prog.showDecimal
+	if isResult {
+		show = prog.showResult
+	}
+
+	lo, hi := show(r.Lo), show(r.Hi)
+	s := lo.text + convert.RangeSep + strings.TrimSpace(hi.text)
+
+	if prog.justVal {
+		return s
+	}
+
+	return s + " " + prog.unitText(u, false) + prog.residualText(lo, hi)
+}
+
+// displayTolerance returns the largest value which would be displayed as
+// zero with the display precision
+func (prog *prog) displayTolerance() float64 {
+	return math.Pow10(-prog.displayPrec) / 2 //nolint:mnd
+}
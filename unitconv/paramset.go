@@ -1,9 +1,14 @@
 package main
 
 import (
+	"maps"
+	"slices"
+
 	"github.com/nickwells/param.mod/v7/param"
-	"github.com/nickwells/param.mod/v7/paramset"
+	"github.com/nickwells/param.mod/v7/phelp"
+	"github.com/nickwells/unittools/convert"
 	"github.com/nickwells/unittools/internal/utparams"
+	"github.com/nickwells/verbose.mod/verbose"
 	"github.com/nickwells/versionparams.mod/versionparams"
 )
 
@@ -11,14 +16,95 @@ import (
 func makeParamSet(prog *prog) *param.PSet {
 	pName := utparams.ProgNameUnitconv
 
-	return paramset.New(
+	return param.NewSet(paramErrHelper{StdHelp: phelp.NewStdHelp(), prog: prog},
 		versionparams.AddParams,
+		verbose.AddParams,
 
 		addParams(prog),
 		addNotes(prog),
 		addExamples,
 		utparams.AddRefs(pName),
+		utparams.AddCompletionParams(pName,
+			utparams.CompletionArg{
+				ParamName: paramNameFamily,
+				Kind:      utparams.CompleteFamily,
+			},
+			utparams.CompletionArg{
+				ParamName: paramNameFrom,
+				Kind:      utparams.CompleteUnit,
+			},
+			utparams.CompletionArg{
+				ParamName: paramNameTo,
+				Kind:      utparams.CompleteUnit,
+				IsList:    true,
+			},
+			utparams.CompletionArg{
+				ParamName: paramNameNearestIgnoreTag,
+				Kind:      utparams.CompleteTag,
+				IsList:    true,
+			},
+			utparams.CompletionArg{
+				ParamName: paramNameToSystem,
+				Kind:      utparams.CompleteParamVals,
+				Vals:      systemNames,
+			},
+			utparams.CompletionArg{
+				ParamName: paramNamePreset,
+				Kind:      utparams.CompleteParamVals,
+				Vals:      prog.completionPresetNames,
+			},
+		),
 
 		utparams.SetProgramDescription(pName),
+		setConfigFile,
 	)
 }
+
+// paramErrHelper is the standard helper except that, if errors are to be
+// reported as JSON, any errors in the parameters are reported as JSON
+// records with the bad-params category.
+type paramErrHelper struct {
+	*phelp.StdHelp
+	prog *prog
+}
+
+// ErrorHandler reports the errors in the parameters and makes the program
+// exit with a status of esParamErrors.
+func (h paramErrHelper) ErrorHandler(ps *param.PSet) {
+	if !h.prog.errorsAsJSON {
+		h.StdHelp.ErrorHandler(ps)
+		return
+	}
+
+	errMap := ps.Errors()
+
+	for _, name := range slices.Sorted(maps.Keys(errMap)) {
+		for _, err := range errMap[name] {
+			h.prog.reportError(
+				convert.NewError(catBadParams, "%s: %s", name, err))
+		}
+	}
+
+	ps.SetExitStatus(h.prog.exitStatus)
+}
+
+// systemNames returns the names of the systems of measurement
+func systemNames() []string {
+	names := []string{}
+	for _, sys := range convert.Systems() {
+		names = append(names, string(sys))
+	}
+
+	return names
+}
+
+// completionPresetNames returns the names of the presets in the preset
+// file. If the presets cannot be read no names are returned.
+func (prog *prog) completionPresetNames() []string {
+	presets, err := loadPresets(prog.presetFile, false)
+	if err != nil {
+		return nil
+	}
+
+	return presetNames(presets)
+}
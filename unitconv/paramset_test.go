@@ -1,8 +1,10 @@
 package main
 
 import (
+	"bytes"
 	"testing"
 
+	"github.com/nickwells/param.mod/v7/paramset"
 	"github.com/nickwells/testhelper.mod/v2/testhelper"
 )
 
@@ -15,3 +17,80 @@ func TestMakeParamSet(t *testing.T) {
 		panicked, false,
 		panicVal, []string{})
 }
+
+func TestFinalChecks(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		args []string
+	}{
+		{
+			ID: testhelper.MkID("fractions"),
+			args: []string{
+				"-from", "m", "-to", "foot", "-fractions",
+			},
+		},
+		{
+			ID: testhelper.MkID("fractions-and-nearest"),
+			ExpErr: testhelper.MkExpErr(
+				`the "fractions" and "nearest" parameters` +
+					` cannot both be given`),
+			args: []string{
+				"-from", "m", "-nearest", "-fractions",
+			},
+		},
+		{
+			ID: testhelper.MkID("fraction-denominator-and-nearest"),
+			ExpErr: testhelper.MkExpErr(
+				`the "fractions" and "nearest" parameters` +
+					` cannot both be given`),
+			args: []string{
+				"-from", "m", "-nearest", "-fraction-denominator", "16",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		prog := newProg()
+		ps := paramset.NewNoHelpNoExitNoErrRpt(
+			addParams(prog),
+			addNotes(prog),
+		)
+		ps.Parse(tc.args)
+
+		var err error
+		for _, errs := range ps.Errors() {
+			if len(errs) > 0 {
+				err = errs[0]
+				break
+			}
+		}
+
+		testhelper.CheckExpErr(t, err, tc)
+	}
+}
+
+func TestParamErrHelper(t *testing.T) {
+	var stderrBuf bytes.Buffer
+
+	prog := newProg()
+	prog.stderr = &stderrBuf
+
+	ps := paramset.NewNoHelpNoExitNoErrRpt(
+		addParams(prog),
+		addNotes(prog),
+	)
+	ps.Parse([]string{
+		"-from", "m", "-nearest", "-fractions", "-errors-as-json",
+	})
+
+	paramErrHelper{prog: prog}.ErrorHandler(ps)
+
+	testhelper.DiffInt(t, "param errors as JSON", "exit status",
+		prog.exitStatus, esParamErrors)
+	testhelper.DiffString(t, "param errors as JSON", "stderr",
+		stderrBuf.String(),
+		`{"category":"bad-params","exitStatus":1,`+
+			`"message":"Final Checks: the \"fractions\" and \"nearest\"`+
+			` parameters cannot both be given"}`+"\n")
+}
@@ -0,0 +1,357 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/nickwells/english.mod/english"
+	"github.com/nickwells/fileparse.mod/fileparse"
+	"github.com/nickwells/location.mod/location"
+	"github.com/nickwells/param.mod/v7/paction"
+	"github.com/nickwells/param.mod/v7/param"
+	"github.com/nickwells/param.mod/v7/paramset"
+)
+
+// presetConflicts lists groups of parameters of which a preset may give
+// at most one. A parameter given on the command line also overrides any
+// setting in the preset of the other parameters in its group.
+var presetConflicts = [][]string{
+	{paramNameTo, paramNameNearest, paramNameToSystem},
+	{paramNameRoughly, paramNameVeryRoughly},
+	{paramNameFractions, paramNameNearest},
+	{paramNameFractionDenominator, paramNameNearest},
+	{paramNameDelta, paramNameNearest},
+	{paramNameCompound, paramNameNearest},
+}
+
+// presetParam records how to set a parameter that can be given in a preset
+type presetParam struct {
+	setter  param.Setter
+	actions []param.ActionFunc
+}
+
+// presetSetting records a parameter setting in a preset
+type presetSetting struct {
+	name     string
+	value    string
+	hasValue bool
+	loc      location.L
+}
+
+// String returns the setting as it would be given in the preset file
+func (s presetSetting) String() string {
+	if s.hasValue {
+		return s.name + "=" + s.value
+	}
+
+	return s.name
+}
+
+// preset is a named collection of parameter settings
+type preset struct {
+	name     string
+	loc      location.L
+	settings []presetSetting
+}
+
+// addPresetParam adds the parameter to the PSet and records it as one
+// which can be given in a preset. The actions are added to the parameter
+// as post-actions and are also called when the parameter is set from a
+// preset.
+func (prog *prog) addPresetParam(ps *param.PSet,
+	setter param.Setter, actions []param.ActionFunc,
+	name, desc string, opts ...param.ByNameOptFunc,
+) *param.ByName {
+	for _, a := range actions {
+		opts = append(opts, param.PostAction(a))
+	}
+
+	opts = append(opts, param.PostAction(
+		func(loc location.L, _ *param.BaseParam, _ []string) error {
+			if paction.IsACommandLineParam(loc, nil, nil) {
+				prog.cmdLineParams[name] = true
+			}
+
+			return nil
+		}))
+
+	prog.presetParams[name] = presetParam{
+		setter:  setter,
+		actions: actions,
+	}
+
+	return ps.Add(name, setter, desc, opts...)
+}
+
+// setOnCommandLine returns true if any of the named parameters was given
+// on the command line
+func (prog *prog) setOnCommandLine(names ...string) bool {
+	for _, name := range names {
+		if prog.cmdLineParams[name] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// conflictGroup returns the names of the parameters which conflict with
+// the named parameter, including the parameter itself. A parameter may
+// appear in several of the presetConflicts groups and so the names from
+// every group containing it are returned.
+func conflictGroup(name string) []string {
+	names := []string{name}
+
+	for _, group := range presetConflicts {
+		if !slices.Contains(group, name) {
+			continue
+		}
+
+		for _, n := range group {
+			if !slices.Contains(names, n) {
+				names = append(names, n)
+			}
+		}
+	}
+
+	return names
+}
+
+// applySetting sets the parameter from the preset setting and calls any
+// associated actions
+func (prog *prog) applySetting(ps *param.PSet, s presetSetting) error {
+	pp, ok := prog.presetParams[s.name]
+	if !ok {
+		return s.loc.Errorf("the %q parameter cannot be given in a preset",
+			s.name)
+	}
+
+	p, err := ps.GetParamByName(s.name)
+	if err != nil {
+		return s.loc.Error(err.Error())
+	}
+
+	if s.hasValue {
+		err = pp.setter.SetWithVal(s.name, s.value)
+	} else {
+		err = pp.setter.Set(s.name)
+	}
+
+	if err != nil {
+		return s.loc.Error(err.Error())
+	}
+
+	paramParts := []string{s.name}
+	if s.hasValue {
+		paramParts = append(paramParts, s.value)
+	}
+
+	for _, action := range pp.actions {
+		if err := action(s.loc, &p.BaseParam, paramParts); err != nil {
+			return s.loc.Error(err.Error())
+		}
+	}
+
+	return nil
+}
+
+// applyPreset sets the parameters given in the preset. Any setting of a
+// parameter which was given on the command line, or which conflicts with
+// one given on the command line, is skipped.
+func (prog *prog) applyPreset(ps *param.PSet, p *preset) error {
+	for _, s := range p.settings {
+		if prog.setOnCommandLine(conflictGroup(s.name)...) {
+			continue
+		}
+
+		if err := prog.applySetting(ps, s); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// presetParser is the fileparse.LineParser used to read the preset file.
+// Each setting is checked by applying it to a scratch program instance so
+// that errors are found when the file is read.
+type presetParser struct {
+	scratch *prog
+	ps      *param.PSet
+
+	presets map[string]*preset
+	current *preset
+}
+
+// newPresetParser returns a presetParser ready to parse a preset file
+func newPresetParser() *presetParser {
+	scratch := newProg()
+
+	return &presetParser{
+		scratch: scratch,
+		ps:      paramset.NewNoHelpNoExitNoErrRpt(addParams(scratch)),
+		presets: map[string]*preset{},
+	}
+}
+
+// ParseLine parses a line from the preset file. The line is either the
+// name of a preset in square brackets, which starts a new preset, or a
+// parameter setting for the current preset.
+func (pp *presetParser) ParseLine(line string, loc *location.L) error {
+	if strings.HasPrefix(line, "[") {
+		return pp.startPreset(line, *loc)
+	}
+
+	if pp.current == nil {
+		return loc.Errorf("a parameter setting must follow a preset name")
+	}
+
+	return pp.addSetting(line, *loc)
+}
+
+// startPreset starts a new preset
+func (pp *presetParser) startPreset(line string, loc location.L) error {
+	name, ok := strings.CutSuffix(strings.TrimPrefix(line, "["), "]")
+	if !ok {
+		return loc.Errorf("a preset name must be in square brackets: %q",
+			line)
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return loc.Errorf("the preset name must not be empty")
+	}
+
+	if p, exists := pp.presets[name]; exists {
+		return loc.Errorf("the preset %q has already been given at %s",
+			name, p.loc)
+	}
+
+	pp.current = &preset{name: name, loc: loc}
+	pp.presets[name] = pp.current
+
+	return nil
+}
+
+// addSetting checks the parameter setting and adds it to the current
+// preset
+func (pp *presetParser) addSetting(line string, loc location.L) error {
+	name, value, hasValue := strings.Cut(line, "=")
+	name = strings.TrimSpace(name)
+
+	p, err := pp.ps.GetParamByName(name)
+	if err != nil {
+		return loc.Errorf("the preset %q has an unknown parameter: %q",
+			pp.current.name, name)
+	}
+
+	s := presetSetting{
+		name:     p.Name(),
+		value:    strings.TrimSpace(value),
+		hasValue: hasValue,
+		loc:      loc,
+	}
+
+	group := conflictGroup(s.name)
+
+	for _, prev := range pp.current.settings {
+		if slices.Contains(group, prev.name) {
+			return loc.Errorf(
+				"the preset %q has already set %q (at %s)",
+				pp.current.name, prev.name, prev.loc)
+		}
+	}
+
+	if err := pp.scratch.applySetting(pp.ps, s); err != nil {
+		return err
+	}
+
+	pp.current.settings = append(pp.current.settings, s)
+
+	return nil
+}
+
+// loadPresets reads the presets from the named file. A missing file is
+// not an error unless presets are required.
+func loadPresets(fileName string, required bool) (map[string]*preset, error) {
+	if _, err := os.Stat(fileName); errors.Is(err, fs.ErrNotExist) {
+		if required {
+			return nil,
+				fmt.Errorf("the preset file %q does not exist", fileName)
+		}
+
+		return map[string]*preset{}, nil
+	}
+
+	pp := newPresetParser()
+
+	errs := fileparse.New("unitconv presets", pp).Parse(fileName)
+	if len(errs) != 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return pp.presets, nil
+}
+
+// usePreset loads the presets, if they are needed, and applies the chosen
+// preset, if any.
+func (prog *prog) usePreset(ps *param.PSet) error {
+	if prog.presetName == "" && !prog.listPresets {
+		return nil
+	}
+
+	presets, err := loadPresets(prog.presetFile, prog.presetName != "")
+	if err != nil {
+		return err
+	}
+
+	prog.presets = presets
+
+	if prog.presetName == "" {
+		return nil
+	}
+
+	p, ok := presets[prog.presetName]
+	if !ok {
+		return fmt.Errorf("there is no preset called %q in %q."+
+			" The available presets are: %s",
+			prog.presetName, prog.presetFile,
+			english.JoinQuoted(presetNames(presets), ", ", " and "))
+	}
+
+	return prog.applyPreset(ps, p)
+}
+
+// presetNames returns the sorted names of the presets
+func presetNames(presets map[string]*preset) []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
+// showPresets reports the available presets and their settings
+func (prog *prog) showPresets() {
+	if len(prog.presets) == 0 {
+		fmt.Fprintf(prog.stdout, "no presets are defined in %q\n",
+			prog.presetFile)
+
+		return
+	}
+
+	for _, name := range presetNames(prog.presets) {
+		fmt.Fprintln(prog.stdout, name)
+
+		for _, s := range prog.presets[name].settings {
+			fmt.Fprintln(prog.stdout, "    "+s.String())
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestLoadPresets(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		fileName string
+		required bool
+		expNames []string
+	}{
+		{
+			ID:       testhelper.MkID("good"),
+			fileName: "good.cfg",
+			expNames: []string{"carpentry", "temperature"},
+		},
+		{
+			ID:       testhelper.MkID("missing, not required"),
+			fileName: "no-such-file.cfg",
+			expNames: []string{},
+		},
+		{
+			ID:       testhelper.MkID("missing, required"),
+			ExpErr:   testhelper.MkExpErr("does not exist"),
+			fileName: "no-such-file.cfg",
+			required: true,
+		},
+		{
+			ID:       testhelper.MkID("unknown parameter"),
+			ExpErr:   testhelper.MkExpErr(`unknown parameter: "no-such-param"`),
+			fileName: "bad-unknown-param.cfg",
+		},
+		{
+			ID: testhelper.MkID("parameter not allowed"),
+			ExpErr: testhelper.MkExpErr(
+				`the "from" parameter cannot be given in a preset`),
+			fileName: "bad-not-presetable.cfg",
+		},
+		{
+			ID: testhelper.MkID("duplicate preset"),
+			ExpErr: testhelper.MkExpErr(
+				`the preset "p1" has already been given`),
+			fileName: "bad-duplicate-preset.cfg",
+		},
+		{
+			ID: testhelper.MkID("conflicting parameters"),
+			ExpErr: testhelper.MkExpErr(
+				`the preset "p1" has already set "to"`),
+			fileName: "bad-conflict.cfg",
+		},
+		{
+			ID:       testhelper.MkID("bad value"),
+			ExpErr:   testhelper.MkExpErr(`could not interpret "three"`),
+			fileName: "bad-value.cfg",
+		},
+		{
+			ID: testhelper.MkID("setting before the preset name"),
+			ExpErr: testhelper.MkExpErr(
+				"a parameter setting must follow a preset name"),
+			fileName: "bad-no-name.cfg",
+		},
+	}
+
+	for _, tc := range testCases {
+		presets, err := loadPresets(
+			filepath.Join(testDataDir, "presets", tc.fileName), tc.required)
+		if testhelper.CheckExpErr(t, err, tc) && err == nil {
+			testhelper.DiffStringSlice(t, tc.IDStr(), "preset names",
+				presetNames(presets), tc.expNames)
+		}
+	}
+}
@@ -2,31 +2,26 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"math"
-	"slices"
-	"strconv"
+	"os"
 	"strings"
 
-	"github.com/nickwells/english.mod/english"
 	"github.com/nickwells/mathutil.mod/v2/mathutil"
 	"github.com/nickwells/units.mod/v2/units"
+	"github.com/nickwells/unittools/convert"
 	"github.com/nickwells/verbose.mod/verbose"
 )
 
-const (
-	esBadConversion = 1 + iota
-)
-
-type converted struct {
-	vu              units.ValUnit
-	absWholeNumDiff float64
-	absLogVal       float64
-}
-
 // prog holds program parameters and status
 type prog struct {
 	exitStatus int
 	stack      *verbose.Stack
+
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+
 	// parameters
 	unitFamily *units.Family
 
@@ -36,19 +31,44 @@ type prog struct {
 	unitFrom units.Unit
 	unitTo   []units.Unit
 
-	val float64
+	valStr   string
+	valGiven bool
+	val      float64
 
 	nearestVal        bool
 	nearestCount      int
 	nearestPrecision  float64
 	nearestIgnoreTags []units.Tag
 
+	toSystem convert.System
+	compound bool
+	delta    bool
+
 	justVal        bool
 	roughly        bool
 	roughPrecision float64
 
 	displayWidth int
 	displayPrec  int
+	unitStyle    unitStyle
+
+	fractions     bool
+	fractionDenom int64
+
+	errorsAsJSON bool
+
+	serveAddr string
+	coprocess bool
+
+	presetName  string
+	presetFile  string
+	listPresets bool
+	presets     map[string]*preset
+
+	// presetParams records the parameters which can be given in a preset
+	presetParams map[string]presetParam
+	// cmdLineParams records the preset parameters given on the command line
+	cmdLineParams map[string]bool
 }
 
 // newProg returns a new Prog instance with the default values set
@@ -63,12 +83,22 @@ func newProg() *prog {
 	return &prog{
 		stack: &verbose.Stack{},
 
-		val:          1,
+		stdin:  os.Stdin,
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+
+		valStr:       "1",
 		displayWidth: 0,
 		displayPrec:  dfltDisplayPrec,
+		unitStyle:    unitStyleName,
 
 		nearestCount:     dfltNearestCount,
 		nearestPrecision: dfltNearestPrecision,
+
+		presetFile: dfltPresetFile(),
+
+		presetParams:  map[string]presetParam{},
+		cmdLineParams: map[string]bool{},
 	}
 }
 
@@ -80,243 +110,393 @@ func (prog *prog) setExitStatus(es int) {
 	}
 }
 
-// getUnitFrom populates the unitFrom member
+// getUnitFrom populates the unitFrom member. If no family has been given
+// it will search all the families and set the unitFamily member to the one
+// family having the named unit.
 func (prog *prog) getUnitFrom() error {
-	var err error
-
-	if prog.unitFamily != nil {
-		prog.unitFrom, err = prog.unitFamily.GetUnit(prog.unitFromName)
-		return err
+	u, err := convert.FindUnit(prog.unitFamily, prog.unitFromName)
+	if err != nil {
+		return prog.addFamilyHint(err)
 	}
 
-	fNames := []string{}
+	prog.unitFrom = u
+	prog.unitFamily = u.Family()
 
-	for _, f := range units.GetFamilies() {
-		u, err := f.GetUnit(prog.unitFromName)
-		if err == nil {
-			prog.unitFamily = f
-			prog.unitFrom = u
+	reportUnitChoice(prog.unitFromName, u)
 
-			fNames = append(fNames, f.Name())
-		}
-	}
-
-	switch len(fNames) {
-	case 0:
-		return fmt.Errorf(
-			"there are %d unit-families with a unit called %q: %s",
-			len(fNames),
-			prog.unitFromName,
-			english.JoinQuoted(fNames, ", ", " and "))
-	case 1:
-		return nil
-	default:
+	return nil
+}
 
+// reportUnitChoice reports, in verbose mode, the unit chosen for the given
+// name if the name is not the unit's ID. This shows how names given in the
+// plural, in a different case or with a variant spelling were interpreted.
+func reportUnitChoice(name string, u units.Unit) {
+	if name == u.ID() {
+		return
 	}
 
-	return nil
+	verbose.Printf("the unit name %q was taken to be %q (a unit of %s)\n",
+		name, u.ID(), u.Family().Name())
 }
 
-// calcAbsWholeNumDiff calculates the absolute difference between the value and
-// the nearest whole number.
-func calcAbsWholeNumDiff(v float64) float64 {
-	multiples := []float64{2, 3, 4, 5, 8, 10}
-	wholeNum := math.Round(v)
-	awnd := math.Abs(v - wholeNum)
-
-	for _, m := range multiples {
-		vm := v * m
-		wholeNum := math.Round(vm)
-		altAwnd := math.Abs(vm - wholeNum)
-		awnd = min(altAwnd, awnd)
+// addFamilyHint adds a suggestion to use the family parameter to the
+// message of an ambiguous-unit error.
+func (prog *prog) addFamilyHint(err error) error {
+	ce := convert.AsError(err)
+	if ce.Category != convert.CatAmbiguousUnit {
+		return err
 	}
 
-	return awnd
-}
+	ce.Msg += fmt.Sprintf(". Choose one with the %q parameter",
+		paramNameFamily)
 
-// calcAbsLog calculates the absolute log value of the supplied value. This
-// will give a value that is smallest when the value is equal to 1.
-func calcAbsLog(v float64) float64 {
-	return math.Abs(math.Log(v))
+	return ce
 }
 
-// cmpAbsLog returns -1, 0 or 1 depending on whether the absLogVal of 'a'
-// and 'b' are less than, equal to or greater than each other.
-func cmpAbsLog(a, b converted) int {
-	if a.absLogVal < b.absLogVal {
-		return -1
-	}
+// findTargetUnits populates the unitFrom and unitTo members. If no family
+// has been given it will use the first family having all the units.
+func (prog *prog) findTargetUnits() error {
+	var err error
 
-	if a.absLogVal > b.absLogVal {
-		return 1
+	prog.unitFrom, prog.unitTo, err = convert.FindUnits(
+		prog.unitFamily, prog.unitFromName, prog.unitToNames)
+	if err != nil {
+		return err
 	}
 
-	return 0
-}
+	prog.unitFamily = prog.unitFrom.Family()
 
-// makeCmpConvertedFunc returns a function that will compare the two
-// converted values firstly by how close each is to a whole number (note that
-// the value also includes several fractions - see calcAbsWholeNumDiff). Then
-// if they are the same or only differ by a small amount they are compared by
-// how close they are to one.
-//
-// This is a generated function so that the small difference value can use
-// the nearestPrecision value from the prog struct.
-func (prog *prog) makeCmpConvertedFunc() func(converted, converted) int {
-	return func(a, b converted) int {
-		if a.absWholeNumDiff < b.absWholeNumDiff {
-			if b.absWholeNumDiff-a.absWholeNumDiff < prog.nearestPrecision {
-				return cmpAbsLog(a, b)
-			}
-
-			return -1
-		}
+	reportUnitChoice(prog.unitFromName, prog.unitFrom)
 
-		if a.absWholeNumDiff > b.absWholeNumDiff {
-			if a.absWholeNumDiff-b.absWholeNumDiff < prog.nearestPrecision {
-				return cmpAbsLog(a, b)
-			}
+	for i, u := range prog.unitTo {
+		reportUnitChoice(prog.unitToNames[i], u)
+	}
 
-			return 1
-		}
+	return nil
+}
 
-		return cmpAbsLog(a, b)
+// findNearestVals populates the unitTo and unitToNames slices with units
+// such that the converted values are the closest to small, preferably
+// whole-number values.
+func (prog *prog) findNearestVals() error {
+	if err := prog.getUnitFrom(); err != nil {
+		return err
 	}
+
+	prog.unitTo = convert.Nearest(
+		units.ValUnit{V: prog.val, U: prog.unitFrom},
+		convert.NearestCfg{
+			Count:      prog.nearestCount,
+			Precision:  prog.nearestPrecision,
+			IgnoreTags: prog.nearestIgnoreTags,
+		})
+	prog.setUnitToNames()
+
+	return nil
 }
 
-// findNearestVals populates the unitToNames slice with names such that the
-// converted values are the closest to small, preferably whole-number
-// values. The units are ordered by small, whole numbers first and fractional
-// values second.
-func (prog *prog) findNearestVals() error {
+// findSystemUnits populates the unitTo and unitToNames slices with the most
+// natural unit (or units if a compound value has been requested) in the
+// target measurement system for showing the value.
+func (prog *prog) findSystemUnits() error {
 	if err := prog.getUnitFrom(); err != nil {
 		return err
 	}
 
-	allUnits := prog.unitFamily.GetUnits()
-	unitVals := make([]converted, 0, len(allUnits))
-	fromVal := units.ValUnit{V: prog.val, U: prog.unitFrom}
+	var err error
 
-	for _, u := range allUnits {
-		vu := fromVal.ConvertOrPanic(u)
-		c := converted{
-			vu:              vu,
-			absWholeNumDiff: calcAbsWholeNumDiff(vu.V),
-			absLogVal:       calcAbsLog(vu.V),
-		}
-		unitVals = append(unitVals, c)
+	prog.unitTo, err = convert.SystemTargets(
+		units.ValUnit{V: prog.val, U: prog.unitFrom},
+		prog.toSystem, prog.compound,
+		prog.nearestPrecision, prog.nearestIgnoreTags)
+	if err != nil {
+		return err
 	}
 
-	slices.SortFunc(unitVals, prog.makeCmpConvertedFunc())
+	prog.setUnitToNames()
 
-AvailableUnits:
-	for _, c := range unitVals {
-		if units.Equals(c.vu.U, prog.unitFrom) {
-			continue AvailableUnits
-		}
+	return nil
+}
 
-		for _, tag := range prog.nearestIgnoreTags {
-			if c.vu.U.HasTag(tag) {
-				continue AvailableUnits
-			}
-		}
+// setUnitToNames sets the unitToNames from the unitTo slice
+func (prog *prog) setUnitToNames() {
+	prog.unitToNames = []string{}
+	for _, u := range prog.unitTo {
+		prog.unitToNames = append(prog.unitToNames, u.ID())
+	}
+}
 
-		prog.unitTo = append(prog.unitTo, c.vu.U)
-		prog.unitToNames = append(prog.unitToNames, c.vu.U.ID())
+// resolveUnits finds the units to convert from and to
+func (prog *prog) resolveUnits() error {
+	if prog.nearestVal {
+		return prog.findNearestVals()
+	}
 
-		if len(prog.unitTo) >= prog.nearestCount {
-			break AvailableUnits
-		}
+	if prog.toSystem != "" {
+		return prog.findSystemUnits()
 	}
 
-	return nil
+	return prog.findTargetUnits()
+}
+
+// checkPrecision reports a precision-loss error if the converted value
+// would be shown as zero even though it is not zero
+func (prog *prog) checkPrecision(v units.ValUnit) {
+	if err := convert.CheckPrecision(v, prog.displayPrec); err != nil {
+		prog.reportError(err)
+	}
 }
 
-// showNearest shows the alternative units most likely to be the value.
-func (prog *prog) showNearest(v units.ValUnit, fmtStr string) {
+// showNearest shows the alternative units most likely to be the value. If
+// only some of the conversions fail the exit status, unless it was already
+// set, shows a partial failure rather than the status of the failures.
+func (prog *prog) showNearest(v units.ValUnit, indent string) {
+	failures := 0
+	exitStatus := prog.exitStatus
+
 	for i, unitTo := range prog.unitTo {
 		converted, err := v.Convert(unitTo)
 		if err != nil {
-			fmt.Println(err)
-			prog.setExitStatus(esBadConversion)
+			prog.reportError(err)
 
-			return
+			failures++
+
+			continue
 		}
 
 		if prog.roughly {
 			converted.V = mathutil.Roughly(converted.V, prog.roughPrecision)
 		}
 
-		fmt.Printf(fmtStr, converted, prog.unitToNames[i])
-	}
-}
-
-// formatString returns the format string to display a ValUnit
-func (prog *prog) formatString() string {
-	fmtIntro := "%" +
-		strconv.Itoa(prog.displayWidth) +
-		"." +
-		strconv.Itoa(prog.displayPrec)
+		prog.checkPrecision(converted)
 
-	if prog.justVal {
-		return fmtIntro + "f"
+		fmt.Fprintf(prog.stdout, "%s%s\t%s\n",
+			indent, prog.formatResult(converted), prog.unitToNames[i])
 	}
 
-	return fmtIntro + "u"
+	if failures > 0 && failures < len(prog.unitTo) {
+		prog.exitStatus = exitStatus
+		prog.setExitStatus(esPartialFailure)
+	}
 }
 
 // run is the starting point for the program, it is called from main()
 // after the command-line parameters have been parsed.
 func (prog *prog) run() {
-	v := units.ValUnit{V: prog.val, U: prog.unitFrom}
+	if prog.listPresets {
+		prog.showPresets()
+
+		return
+	}
+
+	if prog.serveAddr != "" {
+		prog.serve()
+
+		return
+	}
+
+	if prog.coprocess {
+		prog.runCoprocess()
+
+		return
+	}
+
+	if convert.IsRange(prog.valStr) {
+		prog.convertRange()
+
+		return
+	}
+
+	var err error
+
+	prog.val, err = convert.ParseValue(prog.valStr)
+	if err != nil {
+		prog.reportError(err)
+		return
+	}
 
-	fmtStr := prog.formatString()
+	if err := prog.resolveUnits(); err != nil {
+		prog.reportError(err)
+		return
+	}
+
+	v := units.ValUnit{V: prog.val, U: prog.unitFrom}
 
 	var s string
 	if !prog.justVal {
-		s = fmt.Sprintf(fmtStr+" = ", v)
-		fmt.Println(s)
+		s = prog.formatValUnit(v) + " = "
+		fmt.Fprintln(prog.stdout, s)
 	}
 
 	indent := strings.Repeat(" ", len(s))
 
 	if prog.nearestVal {
-		prog.showNearest(v, indent+fmtStr+"\t%s\n")
+		prog.showNearest(v, indent)
 
 		return
 	}
 
-	for i, unitTo := range prog.unitTo {
-		converted, err := v.Convert(unitTo)
-		if err != nil {
-			fmt.Println(err)
-			prog.setExitStatus(esBadConversion)
+	var results []units.ValUnit
+
+	if prog.delta {
+		results, err = convert.CompoundDelta(v, prog.unitTo, prog.roughPrecision)
+	} else {
+		if prog.valGiven && convert.MayBeDelta(v.U, prog.unitTo) {
+			prog.reportWarning(fmt.Sprintf(
+				"%s has been converted as an absolute value."+
+					" If it is a difference between two values"+
+					" give the %q parameter",
+				prog.formatValUnit(v), paramNameDelta))
+		}
 
-			return
+		results, err = convert.Compound(v, prog.unitTo, prog.roughPrecision)
+	}
+
+	for _, converted := range results {
+		prog.checkPrecision(converted)
+
+		fmt.Fprintln(prog.stdout, prog.formatResult(converted))
+	}
+
+	if err != nil {
+		prog.reportError(err)
+	}
+}
+
+// convertRange converts a range of values. The units are chosen, where
+// necessary, using the end of the range furthest from zero. If there are
+// several target units, as for a compound value, each end of the range is
+// shown as a compound value. Otherwise the range is converted into each
+// target unit in turn.
+func (prog *prog) convertRange() {
+	r, err := convert.ParseRange(prog.valStr)
+	if err != nil {
+		prog.reportError(err)
+		return
+	}
+
+	prog.val = r.Hi
+	if math.Abs(r.Lo) > math.Abs(r.Hi) {
+		prog.val = r.Lo
+	}
+
+	if err := prog.resolveUnits(); err != nil {
+		prog.reportError(err)
+		return
+	}
+
+	var s string
+	if !prog.justVal {
+		s = prog.formatRange(r, prog.unitFrom, false) + " = "
+		fmt.Fprintln(prog.stdout, s)
+	}
+
+	if prog.nearestVal {
+		prog.showNearestRange(r, strings.Repeat(" ", len(s)))
+
+		return
+	}
+
+	if len(prog.unitTo) > 1 {
+		prog.showCompoundRange(r)
+
+		return
+	}
+
+	for _, unitTo := range prog.unitTo {
+		if converted, ok := prog.convertRangeTo(r, unitTo); ok {
+			fmt.Fprintln(prog.stdout,
+				prog.formatRange(converted, unitTo, true))
 		}
+	}
+}
 
-		if prog.roughly {
-			converted.V = mathutil.Roughly(converted.V, prog.roughPrecision)
+// convertRangeTo converts the range into the unit, as a difference if the
+// delta parameter was given, and checks the precision of the converted
+// ends. It reports any error and returns false if the range could not be
+// converted.
+func (prog *prog) convertRangeTo(
+	r convert.Range, unitTo units.Unit,
+) (convert.Range, bool) {
+	conv := convert.ConvertRange
+	if prog.delta {
+		conv = convert.ConvertRangeDelta
+	}
+
+	converted, err := conv(r, prog.unitFrom, unitTo)
+	if err != nil {
+		prog.reportError(err)
+		return converted, false
+	}
+
+	if prog.roughly {
+		converted = convert.MakeRange(
+			mathutil.Roughly(converted.Lo, prog.roughPrecision),
+			mathutil.Roughly(converted.Hi, prog.roughPrecision))
+	}
+
+	prog.checkPrecision(units.ValUnit{V: converted.Lo, U: unitTo})
+	prog.checkPrecision(units.ValUnit{V: converted.Hi, U: unitTo})
+
+	return converted, true
+}
+
+// showNearestRange shows the range converted into each of the nearest
+// units. If only some of the conversions fail the exit status is set as
+// for showNearest.
+func (prog *prog) showNearestRange(r convert.Range, indent string) {
+	failures := 0
+	exitStatus := prog.exitStatus
+
+	for i, unitTo := range prog.unitTo {
+		converted, ok := prog.convertRangeTo(r, unitTo)
+		if !ok {
+			failures++
+
+			continue
 		}
 
-		if i != len(prog.unitTo)-1 {
-			intPart := math.Floor(converted.V)
-			fracPart := converted.V - intPart
-			converted.V = intPart
-			backVal := units.ValUnit{V: fracPart, U: unitTo}
+		fmt.Fprintf(prog.stdout, "%s%s\t%s\n",
+			indent, prog.formatRange(converted, unitTo, true),
+			prog.unitToNames[i])
+	}
 
-			convertedBack, err := backVal.Convert(prog.unitFrom)
-			if err != nil {
-				fmt.Println(err)
-				prog.setExitStatus(esBadConversion)
+	if failures > 0 && failures < len(prog.unitTo) {
+		prog.exitStatus = exitStatus
+		prog.setExitStatus(esPartialFailure)
+	}
+}
 
-				return
-			}
+// showCompoundRange shows each end of the range as a compound value in the
+// target units. The parts of each end are formatted as for formatResult
+// and the two ends are separated by convert.RangeSep.
+func (prog *prog) showCompoundRange(r convert.Range) {
+	compound := convert.Compound
+	if prog.delta {
+		compound = convert.CompoundDelta
+	}
+
+	ends := make([]string, 0, 2) //nolint:mnd
 
-			v.V = convertedBack.V
+	for _, v := range []float64{r.Lo, r.Hi} {
+		results, err := compound(units.ValUnit{V: v, U: prog.unitFrom},
+			prog.unitTo, prog.roughPrecision)
+		if err != nil {
+			prog.reportError(err)
+			return
 		}
 
-		fmt.Printf(fmtStr, converted)
-		fmt.Println()
+		parts := make([]string, 0, len(results))
+
+		for _, converted := range results {
+			prog.checkPrecision(converted)
+
+			parts = append(parts,
+				strings.TrimSpace(prog.formatResult(converted)))
+		}
+
+		ends = append(ends, strings.Join(parts, " "))
 	}
+
+	fmt.Fprintln(prog.stdout, strings.Join(ends, convert.RangeSep))
 }
@@ -0,0 +1,506 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/nickwells/param.mod/v7/paramset"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+	"github.com/nickwells/units.mod/v2/units"
+)
+
+const (
+	testDataDir = "testdata"
+	runSubDir   = "run"
+)
+
+var gfc = testhelper.GoldenFileCfg{
+	DirNames:               []string{testDataDir, runSubDir},
+	Sfx:                    "txt",
+	UpdFlagName:            "upd-gf",
+	KeepBadResultsFlagName: "keep-bad-results",
+}
+
+func init() {
+	gfc.AddUpdateFlag()
+	gfc.AddKeepBadResultsFlag()
+}
+
+func TestRun(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		args          []string
+		expExitStatus int
+	}{
+		{
+			ID:   testhelper.MkID("ordinary"),
+			args: []string{"-from", "chain", "-to", "metre", "-val", "80"},
+		},
+		{
+			ID:   testhelper.MkID("ordinary-multi-family"),
+			args: []string{"-from", "pint", "-to", "litre"},
+		},
+		{
+			ID: testhelper.MkID("ordinary-width-prec"),
+			args: []string{
+				"-from", "mile", "-to", "km", "-val", "26.2",
+				"-width", "12", "-prec", "3",
+			},
+		},
+		{
+			ID: testhelper.MkID("temperature"),
+			args: []string{
+				"-from", "F", "-to", "C", "-val", "98.6",
+			},
+		},
+		{
+			ID: testhelper.MkID("temperature-negative"),
+			args: []string{
+				"-from", "C", "-to", "K", "-val", "-40",
+			},
+		},
+		{
+			ID: testhelper.MkID("compound"),
+			args: []string{
+				"-f", "length", "-from", "m", "-to", "foot,inch",
+				"-val", "1.8",
+			},
+		},
+		{
+			ID: testhelper.MkID("compound-three-units"),
+			args: []string{
+				"-from", "kg", "-to", "stone,pound,ounce",
+				"-val", "80",
+			},
+		},
+		{
+			ID: testhelper.MkID("compound-mass-roughly"),
+			args: []string{
+				"-from", "kg", "-to", "stone,pound",
+				"-val", "80", "-roughly",
+			},
+		},
+		{
+			ID: testhelper.MkID("nearest"),
+			args: []string{
+				"-f", "length", "-from", "m", "-val", "10.05534",
+				"-nearest",
+			},
+		},
+		{
+			ID: testhelper.MkID("nearest-roughly"),
+			args: []string{
+				"-f", "length", "-from", "m", "-val", "10.05534",
+				"-nearest", "-roughly",
+				"-nearest-ignore-tag", "colloquial",
+			},
+		},
+		{
+			ID: testhelper.MkID("nearest-count"),
+			args: []string{
+				"-from", "litre", "-val", "4.54609",
+				"-nearest", "-nearest-count", "3",
+			},
+		},
+		{
+			ID: testhelper.MkID("roughly"),
+			args: []string{
+				"-from", "chain", "-to", "m", "-val", "80", "-roughly",
+			},
+		},
+		{
+			ID: testhelper.MkID("very-roughly"),
+			args: []string{
+				"-from", "chain", "-to", "m", "-val", "80", "-very-roughly",
+			},
+		},
+		{
+			ID: testhelper.MkID("just-value"),
+			args: []string{
+				"-from", "chain", "-to", "m", "-val", "80", "-just-val",
+			},
+		},
+		{
+			ID: testhelper.MkID("just-value-compound"),
+			args: []string{
+				"-from", "kg", "-to", "stone,pound", "-val", "80", "-s",
+			},
+		},
+		{
+			ID: testhelper.MkID("just-value-temperature"),
+			args: []string{
+				"-from", "C", "-to", "F", "-val", "100", "-s",
+			},
+		},
+		{
+			ID: testhelper.MkID("to-system-metric"),
+			args: []string{
+				"-f", "length", "-from", "m", "-val", "0.002",
+				"-to-system", "metric",
+			},
+		},
+		{
+			ID: testhelper.MkID("to-system-no-family"),
+			args: []string{
+				"-from", "m", "-val", "0.002", "-to-system", "metric",
+			},
+		},
+		{
+			ID: testhelper.MkID("to-system-imperial"),
+			args: []string{
+				"-f", "length", "-from", "m", "-val", "300",
+				"-to-system", "imperial",
+			},
+		},
+		{
+			ID: testhelper.MkID("to-system-imperial-large"),
+			args: []string{
+				"-f", "length", "-from", "m", "-val", "5000",
+				"-to-system", "imperial",
+			},
+		},
+		{
+			ID: testhelper.MkID("to-system-no-units"),
+			args: []string{
+				"-from", "C", "-val", "20", "-to-system", "imperial",
+			},
+			expExitStatus: esFamilyMismatch,
+		},
+		{
+			ID: testhelper.MkID("to-system-imperial-compound"),
+			args: []string{
+				"-f", "length", "-from", "m", "-val", "1.8",
+				"-to-system", "imperial", "-compound",
+			},
+		},
+		{
+			ID: testhelper.MkID("bad-value"),
+			args: []string{
+				"-from", "chain", "-to", "m", "-val", "eighty",
+			},
+			expExitStatus: esBadValue,
+		},
+		{
+			ID: testhelper.MkID("unknown-unit"),
+			args: []string{
+				"-from", "chian", "-to", "m",
+			},
+			expExitStatus: esUnknownUnit,
+		},
+		{
+			ID: testhelper.MkID("unknown-unit-suggestions"),
+			args: []string{
+				"-from", "metr", "-to", "foot",
+			},
+			expExitStatus: esUnknownUnit,
+		},
+		{
+			ID: testhelper.MkID("unknown-unit-suggestions-json"),
+			args: []string{
+				"-f", "mass", "-from", "kg", "-to", "pund",
+				"-errors-as-json",
+			},
+			expExitStatus: esUnknownUnit,
+		},
+		{
+			ID: testhelper.MkID("normalised-unit-names"),
+			args: []string{
+				"-from", "Metres", "-to", "FEET",
+			},
+		},
+		{
+			ID: testhelper.MkID("unit-style-name-rounded"),
+			args: []string{
+				"-family", "distance",
+				"-from", "inch", "-to", "foot", "-val", "12.0000001",
+			},
+		},
+		{
+			ID: testhelper.MkID("unit-style-abbrev-compound"),
+			args: []string{
+				"-family", "distance", "-from", "metre", "-val", "2.5",
+				"-to-system", "imperial", "-compound",
+				"-unit-style", "abbrev",
+			},
+		},
+		{
+			ID: testhelper.MkID("unit-style-id-nearest"),
+			args: []string{
+				"-family", "distance", "-from", "km", "-nearest",
+				"-unit-style", "id",
+			},
+		},
+		{
+			ID: testhelper.MkID("fractions-auto"),
+			args: []string{
+				"-family", "distance",
+				"-from", "mm", "-to", "inch", "-val", "84.1375",
+				"-fractions",
+			},
+		},
+		{
+			ID: testhelper.MkID("fractions-denominator-residual"),
+			args: []string{
+				"-family", "distance",
+				"-from", "mm", "-to", "inch", "-val", "84",
+				"-fraction-denominator", "16", "-unit-style", "abbrev",
+			},
+		},
+		{
+			ID: testhelper.MkID("fractions-zero"),
+			args: []string{
+				"-family", "distance",
+				"-from", "m", "-to", "foot", "-val", "0",
+				"-fractions",
+			},
+		},
+		{
+			ID: testhelper.MkID("fractions-proper"),
+			args: []string{
+				"-family", "distance",
+				"-from", "mm", "-to", "inch", "-val", "7.9375",
+				"-fractions",
+			},
+		},
+		{
+			ID: testhelper.MkID("fractions-compound"),
+			args: []string{
+				"-family", "distance", "-from", "metre", "-val", "2.5",
+				"-to-system", "imperial", "-compound", "-fractions",
+			},
+		},
+		{
+			ID: testhelper.MkID("range-temperature"),
+			args: []string{
+				"-from", "F", "-to", "C", "-val", "32..104",
+			},
+		},
+		{
+			ID: testhelper.MkID("range-param-just-value"),
+			args: []string{
+				"-from", "F", "-to", "C", "-range", "104,32", "-s",
+			},
+		},
+		{
+			ID: testhelper.MkID("range-fractions"),
+			args: []string{
+				"-family", "distance", "-from", "mm", "-to", "inch",
+				"-range", "80,84", "-fractions",
+			},
+		},
+		{
+			ID: testhelper.MkID("range-to-system"),
+			args: []string{
+				"-family", "distance", "-from", "metre",
+				"-to-system", "imperial", "-val", "1..3",
+			},
+		},
+		{
+			ID: testhelper.MkID("range-to-system-compound"),
+			args: []string{
+				"-family", "distance", "-from", "metre",
+				"-to-system", "imperial", "-compound", "-val", "1.7..1.9",
+			},
+		},
+		{
+			ID: testhelper.MkID("range-compound"),
+			args: []string{
+				"-family", "distance", "-from", "metre",
+				"-to", "foot,inch", "-val", "1.7..1.9", "-fractions",
+			},
+		},
+		{
+			ID: testhelper.MkID("range-nearest"),
+			args: []string{
+				"-family", "distance", "-from", "metre",
+				"-nearest", "-nearest-count", "3", "-val", "10..20",
+			},
+		},
+		{
+			ID: testhelper.MkID("range-delta"),
+			args: []string{
+				"-from", "C", "-to", "F", "-val", "10..20", "-delta",
+			},
+		},
+		{
+			ID: testhelper.MkID("range-bad-value"),
+			args: []string{
+				"-from", "F", "-to", "C", "-val", "32..hot",
+			},
+			expExitStatus: esBadValue,
+		},
+		{
+			ID: testhelper.MkID("delta"),
+			args: []string{
+				"-from", "C", "-to", "F", "-val", "10", "-delta",
+			},
+		},
+		{
+			ID: testhelper.MkID("delta-warning"),
+			args: []string{
+				"-from", "C", "-to", "F", "-val", "10",
+			},
+		},
+		{
+			ID: testhelper.MkID("delta-warning-json"),
+			args: []string{
+				"-from", "C", "-to", "F", "-val", "10", "-errors-as-json",
+			},
+		},
+		{
+			ID: testhelper.MkID("delta-no-warning-default-value"),
+			args: []string{
+				"-from", "C", "-to", "F",
+			},
+		},
+		{
+			ID: testhelper.MkID("ambiguous-unit"),
+			args: []string{
+				"-from", "minute", "-nearest",
+			},
+			expExitStatus: esAmbiguousUnit,
+		},
+		{
+			ID: testhelper.MkID("family-mismatch"),
+			args: []string{
+				"-from", "chain", "-to", "kg",
+			},
+			expExitStatus: esFamilyMismatch,
+		},
+		{
+			ID: testhelper.MkID("family-mismatch-json"),
+			args: []string{
+				"-from", "chain", "-to", "kg", "-errors-as-json",
+			},
+			expExitStatus: esFamilyMismatch,
+		},
+		{
+			ID: testhelper.MkID("preset"),
+			args: []string{
+				"-preset-file", "testdata/presets/good.cfg",
+				"-preset", "carpentry", "-from", "m", "-val", "1.8",
+			},
+		},
+		{
+			ID: testhelper.MkID("preset-overridden"),
+			args: []string{
+				"-preset-file", "testdata/presets/good.cfg",
+				"-preset", "carpentry", "-from", "m", "-val", "1.8",
+				"-to", "cm",
+			},
+		},
+		{
+			ID: testhelper.MkID("preset-fractions-nearest"),
+			args: []string{
+				"-preset-file", "testdata/presets/good.cfg",
+				"-preset", "carpentry", "-from", "m", "-val", "1.8",
+				"-nearest", "-nearest-count", "3",
+			},
+		},
+		{
+			ID: testhelper.MkID("list-presets"),
+			args: []string{
+				"-preset-file", "testdata/presets/good.cfg",
+				"-list-presets",
+			},
+		},
+		{
+			ID: testhelper.MkID("precision-loss"),
+			args: []string{
+				"-from", "mm", "-to", "km", "-prec", "2",
+			},
+			expExitStatus: esPrecisionLoss,
+		},
+		{
+			ID: testhelper.MkID("nearest-precision-loss"),
+			args: []string{
+				"-family", "distance", "-from", "km", "-nearest",
+				"-nearest-count", "4", "-prec", "2",
+				"-nearest-ignore-tag", "colloquial",
+			},
+			expExitStatus: esPrecisionLoss,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var stdoutBuf, stderrBuf bytes.Buffer
+
+			prog := newProg()
+			prog.stdout = &stdoutBuf
+			prog.stderr = &stderrBuf
+
+			ps := paramset.NewNoHelpNoExitNoErrRpt(
+				addParams(prog),
+				addNotes(prog),
+			)
+			ps.Parse(tc.args)
+
+			if errMap := ps.Errors(); len(errMap) != 0 {
+				t.Log(tc.IDStr())
+				t.Fatalf("\t: unexpected parameter errors: %v", errMap)
+			}
+
+			prog.run()
+
+			testhelper.DiffInt(t, tc.IDStr(), "exit status",
+				prog.exitStatus, tc.expExitStatus)
+
+			gfc.Check(t, tc.IDStr()+" [stdout]",
+				tc.Name+".stdout", stdoutBuf.Bytes())
+			gfc.Check(t, tc.IDStr()+" [stderr]",
+				tc.Name+".stderr", stderrBuf.Bytes())
+		})
+	}
+}
+
+func TestShowNearestExitStatus(t *testing.T) {
+	metre := units.GetOrPanic(units.Distance, "metre")
+	foot := units.GetOrPanic(units.Distance, "foot")
+	kg := units.GetOrPanic(units.Mass, "kg")
+
+	testCases := []struct {
+		testhelper.ID
+		initialStatus int
+		unitTo        []units.Unit
+		expExitStatus int
+	}{
+		{
+			ID:            testhelper.MkID("no-failures"),
+			unitTo:        []units.Unit{foot},
+			expExitStatus: esOK,
+		},
+		{
+			ID:            testhelper.MkID("partial-failure"),
+			unitTo:        []units.Unit{foot, kg},
+			expExitStatus: esPartialFailure,
+		},
+		{
+			ID:            testhelper.MkID("all-failed"),
+			unitTo:        []units.Unit{kg},
+			expExitStatus: esBadConversion,
+		},
+		{
+			ID:            testhelper.MkID("partial-failure-status-already-set"),
+			initialStatus: esPrecisionLoss,
+			unitTo:        []units.Unit{foot, kg},
+			expExitStatus: esPrecisionLoss,
+		},
+	}
+
+	for _, tc := range testCases {
+		var stdoutBuf, stderrBuf bytes.Buffer
+
+		prog := newProg()
+		prog.stdout = &stdoutBuf
+		prog.stderr = &stderrBuf
+		prog.exitStatus = tc.initialStatus
+		prog.unitTo = tc.unitTo
+		prog.setUnitToNames()
+
+		prog.showNearest(units.ValUnit{V: 1, U: metre}, "")
+
+		testhelper.DiffInt(t, tc.IDStr(), "exit status",
+			prog.exitStatus, tc.expExitStatus)
+	}
+}
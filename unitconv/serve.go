@@ -0,0 +1,287 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nickwells/unittools/convert"
+)
+
+// These are the names of the query parameters recognised by the service
+const (
+	queryValue       = "value"
+	queryFrom        = "from"
+	queryTo          = "to"
+	queryFamily      = "family"
+	queryCount       = "count"
+	queryIgnoreTag   = "ignore-tag"
+	queryRoughly     = "roughly"
+	queryVeryRoughly = "very-roughly"
+	queryDelta       = "delta"
+)
+
+// serve runs the program as an HTTP service. It only returns if the
+// service fails.
+func (prog *prog) serve() {
+	const readHeaderTimeout = 10 * time.Second
+
+	srv := &http.Server{
+		Addr:              prog.serveAddr,
+		Handler:           prog.serveMux(),
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	err := srv.ListenAndServe()
+	prog.reportError(convert.NewError(catServeFailure, "%s", err))
+}
+
+// serveMux returns the ServeMux which handles the service's endpoints
+func (prog *prog) serveMux() *http.ServeMux {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /convert", prog.handleConvert)
+	mux.HandleFunc("GET /nearest", prog.handleNearest)
+	mux.HandleFunc("GET /families", handleFamilies)
+	mux.HandleFunc("GET /units", handleUnits)
+
+	return mux
+}
+
+// writeJSON writes the value as the JSON body of the response
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+// writeError writes the error as the JSON body of the response. The HTTP
+// status is chosen according to the category of the error.
+func writeError(w http.ResponseWriter, err error) {
+	rec := makeErrorRecord(err)
+	rec.ExitStatus = 0
+
+	status := http.StatusBadRequest
+	if rec.Category == convert.CatUnknownUnit {
+		status = http.StatusNotFound
+	}
+
+	writeJSON(w, status, rec)
+}
+
+// writeResult writes the result or, if the error is not nil, the error
+func writeResult(w http.ResponseWriter, v any, err error) {
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, v)
+}
+
+// getValue returns the value given by the value query parameter, or 1 if
+// it is not given
+func getValue(r *http.Request) (float64, error) {
+	q := r.URL.Query()
+	if !q.Has(queryValue) {
+		return 1, nil
+	}
+
+	return convert.ParseValue(q.Get(queryValue))
+}
+
+// getBool returns the boolean value of the named query parameter. A
+// parameter given with no value is taken to be true.
+func getBool(r *http.Request, name string) (bool, error) {
+	q := r.URL.Query()
+	if !q.Has(name) {
+		return false, nil
+	}
+
+	s := q.Get(name)
+	if s == "" {
+		return true, nil
+	}
+
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, convert.NewError(catBadRequest,
+			"the %q query parameter (%q) is not a valid boolean value",
+			name, s)
+	}
+
+	return b, nil
+}
+
+// getRoughPrecision returns the rough precision given by the roughly and
+// very-roughly query parameters
+func getRoughPrecision(r *http.Request) (float64, error) {
+	veryRoughly, err := getBool(r, queryVeryRoughly)
+	if err != nil {
+		return 0, err
+	}
+
+	roughly, err := getBool(r, queryRoughly)
+	if err != nil {
+		return 0, err
+	}
+
+	return roughPrecision(roughly, veryRoughly), nil
+}
+
+// getUnitNames returns the unit names given by the named query
+// parameter. The parameter may be repeated and each value may be a comma
+// separated list of names.
+func getUnitNames(r *http.Request, name string) []string {
+	var names []string
+
+	for _, v := range r.URL.Query()[name] {
+		for n := range strings.SplitSeq(v, ",") {
+			if n == "" {
+				continue
+			}
+
+			names = append(names, n)
+		}
+	}
+
+	return names
+}
+
+// getCount returns the value of the count query parameter or zero if it is
+// not given
+func getCount(r *http.Request) (int, error) {
+	q := r.URL.Query()
+	if !q.Has(queryCount) {
+		return 0, nil
+	}
+
+	n, err := strconv.Atoi(q.Get(queryCount))
+	if err != nil || n < 1 {
+		return 0, convert.NewError(catBadRequest,
+			"the %q query parameter (%q) must be a whole number"+
+				" greater than zero",
+			queryCount, q.Get(queryCount))
+	}
+
+	return n, nil
+}
+
+// handleConvert handles the /convert endpoint
+func (prog *prog) handleConvert(w http.ResponseWriter, r *http.Request) {
+	if convert.IsRange(r.URL.Query().Get(queryValue)) {
+		prog.handleConvertRange(w, r)
+		return
+	}
+
+	var (
+		req convert.Request
+		err error
+	)
+
+	if req.Value, err = getValue(r); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if req.RoughPrecision, err = getRoughPrecision(r); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if req.Delta, err = getBool(r, queryDelta); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	req.Family = r.URL.Query().Get(queryFamily)
+	req.From = r.URL.Query().Get(queryFrom)
+	req.To = getUnitNames(r, queryTo)
+
+	res, err := prog.doConvert(req)
+	writeResult(w, res, err)
+}
+
+// handleConvertRange handles requests to the /convert endpoint where the
+// value is a range
+func (prog *prog) handleConvertRange(w http.ResponseWriter, r *http.Request) {
+	var (
+		req rangeRequest
+		err error
+	)
+
+	req.Range, err = convert.ParseRange(r.URL.Query().Get(queryValue))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if req.RoughPrecision, err = getRoughPrecision(r); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if req.Delta, err = getBool(r, queryDelta); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	req.Family = r.URL.Query().Get(queryFamily)
+	req.From = r.URL.Query().Get(queryFrom)
+	req.To = getUnitNames(r, queryTo)
+
+	res, err := prog.doConvertRange(req)
+	writeResult(w, res, err)
+}
+
+// handleNearest handles the /nearest endpoint
+func (prog *prog) handleNearest(w http.ResponseWriter, r *http.Request) {
+	var (
+		req nearestRequest
+		err error
+	)
+
+	if req.Value, err = getValue(r); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if req.RoughPrecision, err = getRoughPrecision(r); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	count, err := getCount(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	req.Cfg, err = prog.makeNearestCfg(count, r.URL.Query()[queryIgnoreTag])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	req.Family = r.URL.Query().Get(queryFamily)
+	req.From = r.URL.Query().Get(queryFrom)
+
+	res, err := prog.doNearest(req)
+	writeResult(w, res, err)
+}
+
+// handleFamilies handles the /families endpoint
+func handleFamilies(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, familyList())
+}
+
+// handleUnits handles the /units endpoint
+func handleUnits(w http.ResponseWriter, r *http.Request) {
+	res, err := unitList(r.URL.Query().Get(queryFamily))
+	writeResult(w, res, err)
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+const serveSubDir = "serve"
+
+var serveGFC = testhelper.GoldenFileCfg{
+	DirNames:               []string{testDataDir, serveSubDir},
+	Sfx:                    "json",
+	UpdFlagName:            "upd-serve-gf",
+	KeepBadResultsFlagName: "keep-bad-serve-results",
+}
+
+func init() {
+	serveGFC.AddUpdateFlag()
+	serveGFC.AddKeepBadResultsFlag()
+}
+
+func TestServe(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		target    string
+		expStatus int
+	}{
+		{
+			ID:        testhelper.MkID("convert"),
+			target:    "/convert?from=chain&to=metre&value=80",
+			expStatus: http.StatusOK,
+		},
+		{
+			ID:        testhelper.MkID("convert-default-value"),
+			target:    "/convert?from=pint&to=litre",
+			expStatus: http.StatusOK,
+		},
+		{
+			ID:        testhelper.MkID("convert-compound"),
+			target:    "/convert?family=length&from=m&to=foot,inch&value=1.8",
+			expStatus: http.StatusOK,
+		},
+		{
+			ID:        testhelper.MkID("convert-roughly"),
+			target:    "/convert?from=chain&to=m&value=80&roughly",
+			expStatus: http.StatusOK,
+		},
+		{
+			ID:        testhelper.MkID("convert-bad-value"),
+			target:    "/convert?from=chain&to=m&value=eighty",
+			expStatus: http.StatusBadRequest,
+		},
+		{
+			ID:        testhelper.MkID("convert-unknown-unit"),
+			target:    "/convert?from=chian&to=m",
+			expStatus: http.StatusNotFound,
+		},
+		{
+			ID:        testhelper.MkID("convert-family-mismatch"),
+			target:    "/convert?from=chain&to=kg",
+			expStatus: http.StatusBadRequest,
+		},
+		{
+			ID:        testhelper.MkID("convert-no-to"),
+			target:    "/convert?from=chain",
+			expStatus: http.StatusBadRequest,
+		},
+		{
+			ID:        testhelper.MkID("convert-delta"),
+			target:    "/convert?from=C&to=F&value=10&delta",
+			expStatus: http.StatusOK,
+		},
+		{
+			ID:        testhelper.MkID("convert-range"),
+			target:    "/convert?from=F&to=C&value=32..104",
+			expStatus: http.StatusOK,
+		},
+		{
+			ID:        testhelper.MkID("convert-range-two-units"),
+			target:    "/convert?from=F&to=C,K&value=32..104",
+			expStatus: http.StatusBadRequest,
+		},
+		{
+			ID:        testhelper.MkID("nearest"),
+			target:    "/nearest?family=length&from=m&value=10.05534&count=3",
+			expStatus: http.StatusOK,
+		},
+		{
+			ID:        testhelper.MkID("nearest-ambiguous-unit"),
+			target:    "/nearest?from=minute",
+			expStatus: http.StatusBadRequest,
+		},
+		{
+			ID:        testhelper.MkID("nearest-bad-tag"),
+			target:    "/nearest?from=chain&ignore-tag=nonesuch",
+			expStatus: http.StatusBadRequest,
+		},
+		{
+			ID:        testhelper.MkID("families"),
+			target:    "/families",
+			expStatus: http.StatusOK,
+		},
+		{
+			ID:        testhelper.MkID("units"),
+			target:    "/units?family=angle",
+			expStatus: http.StatusOK,
+		},
+		{
+			ID:        testhelper.MkID("units-no-family"),
+			target:    "/units",
+			expStatus: http.StatusBadRequest,
+		},
+	}
+
+	prog := newProg()
+	mux := prog.serveMux()
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, tc.target, nil)
+
+			mux.ServeHTTP(rec, req)
+
+			testhelper.DiffInt(t, tc.IDStr(), "HTTP status",
+				rec.Code, tc.expStatus)
+			testhelper.DiffString(t, tc.IDStr(), "Content-Type",
+				rec.Header().Get("Content-Type"), "application/json")
+
+			serveGFC.Check(t, tc.IDStr(), tc.Name, rec.Body.Bytes())
+		})
+	}
+}
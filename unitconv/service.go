@@ -0,0 +1,325 @@
+package main
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/nickwells/mathutil.mod/v2/mathutil"
+	"github.com/nickwells/units.mod/v2/units"
+	"github.com/nickwells/unittools/convert"
+)
+
+// catBadRequest is the category of errors caused by a malformed request to
+// the HTTP service or the co-process
+const catBadRequest convert.Category = "bad-request"
+
+// valUnitJSON is the JSON form of a value in a given unit
+type valUnitJSON struct {
+	Value float64 `json:"value"`
+	Unit  string  `json:"unit"`
+	Text  string  `json:"text"`
+}
+
+// conversionJSON is the JSON form of the result of a conversion
+type conversionJSON struct {
+	From valUnitJSON   `json:"from"`
+	To   []valUnitJSON `json:"to"`
+}
+
+// rangeJSON is the JSON form of a range of values in a given unit
+type rangeJSON struct {
+	Lo   float64 `json:"lo"`
+	Hi   float64 `json:"hi"`
+	Unit string  `json:"unit"`
+	Text string  `json:"text"`
+}
+
+// rangeConversionJSON is the JSON form of the result of converting a range
+// of values
+type rangeConversionJSON struct {
+	From rangeJSON   `json:"from"`
+	To   []rangeJSON `json:"to"`
+}
+
+// familyJSON is the JSON form of a family of units
+type familyJSON struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	BaseUnit    string `json:"baseUnit"`
+}
+
+// unitJSON is the JSON form of a unit
+type unitJSON struct {
+	ID         string      `json:"id"`
+	Name       string      `json:"name"`
+	NamePlural string      `json:"namePlural"`
+	Abbrev     string      `json:"abbrev"`
+	Tags       []units.Tag `json:"tags,omitempty"`
+}
+
+// nearestRequest describes a request for the units in which a value is
+// closest to a small, whole number
+type nearestRequest struct {
+	Family         string
+	From           string
+	Value          float64
+	RoughPrecision float64
+	Cfg            convert.NearestCfg
+}
+
+// rangeRequest describes the conversion of a range of values from one unit
+// into another
+type rangeRequest struct {
+	Family         string
+	From           string
+	To             []string
+	Range          convert.Range
+	RoughPrecision float64
+	Delta          bool
+}
+
+// missingValueError returns the error to report when a required value has
+// not been given in a request
+func missingValueError(name string) error {
+	return convert.NewError(catBadRequest, "the %q value must be given", name)
+}
+
+// lookupFamily returns the named family or nil if the name is empty
+func lookupFamily(fName string) (*units.Family, error) {
+	if fName == "" {
+		return nil, nil
+	}
+
+	f, err := units.GetFamily(fName)
+	if err != nil {
+		return nil, convert.NewError(convert.CatUnknownUnit, "%s", err)
+	}
+
+	return f, nil
+}
+
+// roughPrecision returns the rough precision to use given the roughly and
+// veryRoughly flags
+func roughPrecision(roughly, veryRoughly bool) float64 {
+	if veryRoughly {
+		return veryRoughPrecisionValue
+	}
+
+	if roughly {
+		return roughPrecisionValue
+	}
+
+	return 0
+}
+
+// makeNearestCfg returns the configuration for the nearest search. The
+// count and the tags to ignore are added to the program defaults; a count
+// of zero means that the default count is used.
+func (prog *prog) makeNearestCfg(
+	count int, tagNames []string,
+) (convert.NearestCfg, error) {
+	cfg := convert.NearestCfg{
+		Count:      prog.nearestCount,
+		Precision:  prog.nearestPrecision,
+		IgnoreTags: slices.Clone(prog.nearestIgnoreTags),
+	}
+
+	if count < 0 {
+		return cfg, convert.NewError(catBadRequest,
+			"the count (%d) must be greater than zero", count)
+	}
+
+	if count > 0 {
+		cfg.Count = count
+	}
+
+	for _, t := range tagNames {
+		tag := units.Tag(t)
+		if !tag.IsValid() {
+			return cfg, convert.NewError(catBadRequest,
+				"%q is not a valid unit tag", t)
+		}
+
+		cfg.IgnoreTags = append(cfg.IgnoreTags, tag)
+	}
+
+	return cfg, nil
+}
+
+// makeValUnitJSON returns the JSON form of the ValUnit, formatted as the
+// command line would show it
+func (prog *prog) makeValUnitJSON(v units.ValUnit) valUnitJSON {
+	return valUnitJSON{
+		Value: v.V,
+		Unit:  v.U.ID(),
+		Text:  prog.formatValUnit(v),
+	}
+}
+
+// doConvert performs the conversion and returns the result in JSON form
+func (prog *prog) doConvert(req convert.Request) (conversionJSON, error) {
+	if req.From == "" {
+		return conversionJSON{}, missingValueError(queryFrom)
+	}
+
+	if len(req.To) == 0 {
+		return conversionJSON{}, missingValueError(queryTo)
+	}
+
+	res, err := convert.Convert(req)
+	if err != nil {
+		return conversionJSON{}, prog.addFamilyHint(err)
+	}
+
+	rval := conversionJSON{From: prog.makeValUnitJSON(res.From)}
+	for _, v := range res.To {
+		rval.To = append(rval.To, prog.makeValUnitJSON(v))
+	}
+
+	return rval, nil
+}
+
+// makeRangeJSON returns the JSON form of the range, formatted as the
+// command line would show it
+func (prog *prog) makeRangeJSON(r convert.Range, u units.Unit) rangeJSON {
+	return rangeJSON{
+		Lo:   r.Lo,
+		Hi:   r.Hi,
+		Unit: u.ID(),
+		Text: prog.formatRange(r, u, false),
+	}
+}
+
+// doConvertRange converts both ends of the range and returns the result in
+// JSON form. A range can only be converted into a single unit.
+func (prog *prog) doConvertRange(
+	req rangeRequest,
+) (rangeConversionJSON, error) {
+	f, err := lookupFamily(req.Family)
+	if err != nil {
+		return rangeConversionJSON{}, err
+	}
+
+	if req.From == "" {
+		return rangeConversionJSON{}, missingValueError(queryFrom)
+	}
+
+	if len(req.To) != 1 {
+		return rangeConversionJSON{}, convert.NewError(catBadRequest,
+			"a range of values must be converted into exactly one unit"+
+				" (%d were given)", len(req.To))
+	}
+
+	from, to, err := convert.FindUnits(f, req.From, req.To)
+	if err != nil {
+		return rangeConversionJSON{}, prog.addFamilyHint(err)
+	}
+
+	conv := convert.ConvertRange
+	if req.Delta {
+		conv = convert.ConvertRangeDelta
+	}
+
+	converted, err := conv(req.Range, from, to[0])
+	if err != nil {
+		return rangeConversionJSON{}, err
+	}
+
+	if req.RoughPrecision > 0 {
+		converted = convert.MakeRange(
+			mathutil.Roughly(converted.Lo, req.RoughPrecision),
+			mathutil.Roughly(converted.Hi, req.RoughPrecision))
+	}
+
+	return rangeConversionJSON{
+		From: prog.makeRangeJSON(req.Range, from),
+		To:   []rangeJSON{prog.makeRangeJSON(converted, to[0])},
+	}, nil
+}
+
+// doNearest finds the units in which the value is closest to a small,
+// whole number and returns the converted values in JSON form
+func (prog *prog) doNearest(req nearestRequest) (conversionJSON, error) {
+	f, err := lookupFamily(req.Family)
+	if err != nil {
+		return conversionJSON{}, err
+	}
+
+	if req.From == "" {
+		return conversionJSON{}, missingValueError(queryFrom)
+	}
+
+	from, err := convert.FindUnit(f, req.From)
+	if err != nil {
+		return conversionJSON{}, prog.addFamilyHint(err)
+	}
+
+	v := units.ValUnit{V: req.Value, U: from}
+	rval := conversionJSON{From: prog.makeValUnitJSON(v)}
+
+	for _, u := range convert.Nearest(v, req.Cfg) {
+		converted, err := v.Convert(u)
+		if err != nil {
+			return conversionJSON{}, err
+		}
+
+		if req.RoughPrecision > 0 {
+			converted.V = mathutil.Roughly(converted.V, req.RoughPrecision)
+		}
+
+		rval.To = append(rval.To, prog.makeValUnitJSON(converted))
+	}
+
+	return rval, nil
+}
+
+// familyList returns the JSON form of all the families of units, sorted by
+// name
+func familyList() []familyJSON {
+	rval := []familyJSON{}
+
+	for _, f := range units.GetFamilies() {
+		rval = append(rval, familyJSON{
+			Name:        f.Name(),
+			Description: f.Description(),
+			BaseUnit:    f.BaseUnitName(),
+		})
+	}
+
+	slices.SortFunc(rval, func(a, b familyJSON) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return rval
+}
+
+// unitList returns the JSON form of all the units in the named family,
+// sorted by ID
+func unitList(fName string) ([]unitJSON, error) {
+	if fName == "" {
+		return nil, missingValueError(queryFamily)
+	}
+
+	f, err := lookupFamily(fName)
+	if err != nil {
+		return nil, err
+	}
+
+	rval := []unitJSON{}
+
+	for _, u := range f.GetUnits() {
+		rval = append(rval, unitJSON{
+			ID:         u.ID(),
+			Name:       u.Name(),
+			NamePlural: u.NamePlural(),
+			Abbrev:     u.Abbrev(),
+			Tags:       u.Tags(),
+		})
+	}
+
+	slices.SortFunc(rval, func(a, b unitJSON) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	return rval, nil
+}
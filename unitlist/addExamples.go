@@ -10,6 +10,38 @@ func addExamples(ps *param.PSet) error {
 		"This will show the available units of temperature")
 	ps.AddExample("unitlist -f temperature -u K",
 		"This will show details of the 'K' unit of temperature")
+	ps.AddExample("unitlist -u pint",
+		"This will show details of the 'pint' unit in every"+
+			" family having such a unit")
+	ps.AddExample("unitlist -search gallon",
+		"This will show all the units called gallon, whatever"+
+			" their family")
+	ps.AddExample("unitlist -f volume -compare gallon,US-gallon,litre",
+		"This will show how many of each of the units there are"+
+			" in the others")
+	ps.AddExample("unitlist -f length -min-size 1m -max-size 1km",
+		"This will show the units of length from one metre"+
+			" to one kilometre in size")
+	ps.AddExample(
+		"unitlist -f length -where '(metric or SI) and not historic'",
+		"This will show the units of length which are metric or SI"+
+			" units and are not historic")
+	ps.AddExample(
+		"unitlist -f length -columns name,abbreviation,notes:60"+
+			" -sort-by abbreviation",
+		"This will show the names, abbreviations and notes of the"+
+			" units of length, with wider notes, sorted by abbreviation")
+	ps.AddExample("unitlist -f length -relative-to foot",
+		"This will show the details of the units of length with"+
+			" their sizes given in feet rather than metres")
+	ps.AddExample("unitlist -lint",
+		"This will check the definitions of the units in every"+
+			" family and report any problems found")
+	ps.AddExample("unitlist -summary",
+		"This will show a summary of each family of units")
+	ps.AddExample("unitlist -f length -format csv",
+		"This will show all the details of the units of length"+
+			" as comma-separated values")
 
 	return nil
 }
@@ -2,10 +2,14 @@ package main
 
 import (
 	"errors"
+	"fmt"
 
+	"github.com/nickwells/check.mod/v2/check"
+	"github.com/nickwells/english.mod/english"
 	"github.com/nickwells/param.mod/v7/param"
 	"github.com/nickwells/param.mod/v7/psetter"
 	"github.com/nickwells/unitsetter.mod/v4/unitsetter"
+	"github.com/nickwells/unittools/convert"
 )
 
 const (
@@ -13,11 +17,29 @@ const (
 	paramNameUnit   = "unit"
 
 	paramNameByName      = "by-name"
+	paramNameSortBy      = "sort-by"
+	paramNameColumns     = "columns"
+	paramNameRelativeTo  = "relative-to"
 	paramNameShowDetails = "show-details"
 	paramNameNoHeader    = "no-header"
+	paramNameFormat      = "format"
 
 	paramNameTagged    = "tagged"
 	paramNameNotTagged = "not-tagged"
+	paramNameWhere     = "where"
+
+	paramNameSearch        = "search"
+	paramNameSearchMode    = "search-mode"
+	paramNameSearchAliases = "search-aliases"
+	paramNameSearchNotes   = "search-notes"
+
+	paramNameCompare = "compare"
+
+	paramNameLint    = "lint"
+	paramNameSummary = "summary"
+
+	paramNameMinSize = "min-size"
+	paramNameMaxSize = "max-size"
 )
 
 // addParams will add parameters to the passed ParamSet
@@ -36,10 +58,159 @@ func addParams(prog *prog) param.PSetOptFunc {
 
 		unitParam := ps.Add(paramNameUnit,
 			psetter.String[string]{Value: &prog.uName},
-			"the name of the unit to show. If this is given then"+
-				" a family name must also be given."+
-				" Full details of the unit will be displayed.",
+			"the name of the unit to show."+
+				" Full details of the unit will be displayed."+
+				"\n\n"+
+				"If no family name is given then the details of the"+
+				" unit are shown for every family having a unit"+
+				" with this name. To find units whose names you"+
+				" only partly know, use the"+
+				" '"+paramNameSearch+"' parameter.",
 			param.AltNames("u"),
+			param.SeeAlso(paramNameSearch),
+		)
+
+		searchParam := ps.Add(paramNameSearch,
+			psetter.String[string]{
+				Value: &prog.searchPattern,
+				Checks: []check.ValCk[string]{
+					check.StringLength[string](check.ValGT(0)),
+				},
+			},
+			"search the units in every family for those whose ID,"+
+				" name, plural name or abbreviation matches the"+
+				" pattern and list them, together with their family."+
+				" If a family is given only the units in that family"+
+				" are searched."+
+				" The units listed can be constrained by tag and"+
+				" ordered in the same way as when listing the units"+
+				" in a family.",
+			param.AltNames("find"),
+			param.ValueName("pattern"),
+			param.SeeAlso(paramNameSearchMode,
+				paramNameSearchAliases, paramNameSearchNotes),
+		)
+
+		searchModeParam := ps.Add(paramNameSearchMode,
+			psetter.Enum[searchMode]{
+				Value: &prog.searchMode,
+				AllowedVals: psetter.AllowedVals[searchMode]{
+					searchSubstring: "the pattern can appear anywhere" +
+						" in the name; case is ignored",
+					searchGlob: "the pattern is a shell glob pattern" +
+						" which must match the whole name;" +
+						" case is ignored",
+					searchRegexp: "the pattern is a regular expression;" +
+						" case is significant unless the pattern" +
+						" starts with (?i)",
+				},
+			},
+			"how the search pattern is matched against the names.",
+			param.ValueName("mode"),
+			param.SeeAlso(paramNameSearch),
+		)
+
+		searchAliasesParam := ps.Add(paramNameSearchAliases,
+			psetter.Bool{Value: &prog.searchAliases},
+			"also match the search pattern against the"+
+				" alternative names of the units.",
+			param.SeeAlso(paramNameSearch, paramNameSearchNotes),
+		)
+
+		searchNotesParam := ps.Add(paramNameSearchNotes,
+			psetter.Bool{Value: &prog.searchNotes},
+			"also match the search pattern against the"+
+				" notes describing the units.",
+			param.SeeAlso(paramNameSearch, paramNameSearchAliases),
+		)
+
+		const sizeDesc = " The size is given as a quantity in any unit" +
+			" of the family, such as '1m' or '2.5 feet'; if the" +
+			" number is omitted it is taken to be one." +
+			" Only the sizes of the units are compared; any" +
+			" offsets, such as those of the temperature scales," +
+			" are ignored." +
+			"\n\n" +
+			"This should only be given when listing the units" +
+			" in a family."
+
+		minSizeParam := ps.Add(paramNameMinSize,
+			psetter.String[string]{
+				Value: &prog.minSizeStr,
+				Checks: []check.ValCk[string]{
+					check.StringLength[string](check.ValGT(0)),
+				},
+			},
+			"only show units at least as large as this."+sizeDesc,
+			param.AltNames("min"),
+			param.ValueName("quantity"),
+			param.SeeAlso(paramNameMaxSize),
+		)
+
+		maxSizeParam := ps.Add(paramNameMaxSize,
+			psetter.String[string]{
+				Value: &prog.maxSizeStr,
+				Checks: []check.ValCk[string]{
+					check.StringLength[string](check.ValGT(0)),
+				},
+			},
+			"only show units no larger than this."+sizeDesc,
+			param.AltNames("max"),
+			param.ValueName("quantity"),
+			param.SeeAlso(paramNameMinSize),
+		)
+
+		compareParam := ps.Add(paramNameCompare,
+			psetter.StrList[string]{
+				Value: &prog.compareNames,
+				Checks: []check.ValCk[[]string]{
+					check.SliceLength[[]string](check.ValGE(2)),
+					check.SliceAll[[]string](
+						check.StringLength[string](check.ValGT(0))),
+				},
+			},
+			"compare the units, showing how many of each unit"+
+				" there are in each of the others, together with"+
+				" their tags and notes."+
+				" This can help to distinguish between units with"+
+				" similar names."+
+				" Only the sizes of the units are compared; any"+
+				" offsets, such as those of the temperature scales,"+
+				" are ignored."+
+				"\n\n"+
+				"If no family is given then the first family having"+
+				" all the units is used.",
+			param.ValueName("unit,unit,..."),
+			param.SeeAlso(paramNameUnit),
+		)
+
+		lintParam := ps.Add(paramNameLint,
+			psetter.Bool{Value: &prog.lint},
+			"check the definitions of the units for problems and"+
+				" report any that are found."+
+				" The checks are for aliases shared by several units"+
+				" or which are the names of other units, units with"+
+				" the same conversion as another unit, units"+
+				" without notes or a plural name, unknown tags and"+
+				" missing, shared or misleading abbreviations."+
+				" The program exits with a status of 1 if any problems"+
+				" are found."+
+				"\n\n"+
+				"Every family is checked unless a family is given.",
+			param.SeeAlso(paramNameFamily, paramNameFormat),
+		)
+
+		summaryParam := ps.Add(paramNameSummary,
+			psetter.Bool{Value: &prog.summary},
+			"show a summary of each family of units giving the"+
+				" base unit, the numbers of units and aliases, the"+
+				" number of tags in use, the smallest and largest"+
+				" units, the span between them in orders of magnitude"+
+				" and the number of units whose conversion is not a"+
+				" simple multiplication."+
+				"\n\n"+
+				"Every family is summarised unless a family is given.",
+			param.SeeAlso(paramNameFamily, paramNameFormat),
 		)
 
 		orderParam := ps.Add(paramNameByName,
@@ -48,6 +219,81 @@ func addParams(prog *prog) param.PSetOptFunc {
 				"\n\n"+
 				"This should only be given when listing all the units"+
 				" for a single family.",
+			param.SeeAlso(paramNameSortBy),
+		)
+
+		sortByParam := ps.Add(paramNameSortBy,
+			psetter.Enum[colName]{
+				Value:                    &prog.sortBy,
+				AllowedVals:              colAllowedVals(),
+				AllowInvalidInitialValue: true,
+			},
+			"sort the units by the value in the given column,"+
+				" whether or not that column is shown."+
+				" Numeric columns are sorted by value and the base unit"+
+				" sorts first by the '"+string(colNameBaseMarker)+"'"+
+				" column."+
+				" Units having the same value are left in size order."+
+				"\n\n"+
+				"This should only be given when listing all the units"+
+				" for a single family.",
+			param.ValueName("column"),
+			param.SeeAlso(paramNameByName, paramNameColumns),
+		)
+
+		columnsParam := ps.Add(paramNameColumns,
+			psetter.StrList[string]{
+				Value: &prog.columnSpecs,
+				Checks: []check.ValCk[[]string]{
+					check.SliceLength[[]string](check.ValGT(0)),
+					check.SliceAll[[]string](
+						check.StringLength[string](check.ValGT(0))),
+				},
+			},
+			"the columns to show when listing units, in the order"+
+				" given. The width of a column can be set by following"+
+				" its name with '"+colWidthSep+"' and the width."+
+				" For instance:"+
+				"\n\n"+
+				"name,abbreviation"+colWidthSep+"6,notes"+colWidthSep+"60"+
+				"\n\n"+
+				"The available columns are: "+
+				english.Join(colNames(), ", ", " and ")+"."+
+				" See the '"+paramNameSortBy+"' parameter for a"+
+				" description of each column."+
+				"\n\n"+
+				"This should only be given when listing the units with"+
+				" the '"+string(fmtText)+"' format.",
+			param.ValueName("column[:width],..."),
+			param.SeeAlso(paramNameShowDetails, paramNameSortBy,
+				paramNameRelativeTo),
+		)
+
+		relToParam := ps.Add(paramNameRelativeTo,
+			psetter.String[string]{
+				Value: &prog.relativeTo,
+				Checks: []check.ValCk[string]{
+					check.StringLength[string](check.ValGT(0)),
+				},
+			},
+			"show the conversion factors of the units relative to"+
+				" this unit rather than to the base unit of the family."+
+				" The '"+string(colNameFactor)+"' column shows how many"+
+				" of this unit there are in one of each unit and the"+
+				" '"+string(colNameInverse)+"' column shows the reverse."+
+				" Where the conversion is not a simple multiplication,"+
+				" as with temperature scales, the formula is shown"+
+				" instead."+
+				"\n\n"+
+				"If the '"+paramNameColumns+"' parameter is not given"+
+				" then the detailed listing is shown with the"+
+				" '"+string(colNameInverse)+"' column added."+
+				"\n\n"+
+				"This should only be given when listing the units"+
+				" in a family.",
+			param.AltNames("rel"),
+			param.ValueName("unit"),
+			param.SeeAlso(paramNameColumns, paramNameShowDetails),
 		)
 
 		ps.Add(paramNameTagged,
@@ -60,7 +306,7 @@ func addParams(prog *prog) param.PSetOptFunc {
 				"This should only be given when listing all the units"+
 				" for a single family.",
 			param.AltNames("tag"),
-			param.SeeAlso(paramNameNotTagged),
+			param.SeeAlso(paramNameNotTagged, paramNameWhere),
 		)
 
 		ps.Add(paramNameNotTagged,
@@ -71,16 +317,50 @@ func addParams(prog *prog) param.PSetOptFunc {
 				"\n\n"+
 				"This should only be given when listing all the units"+
 				" for a single family.",
-			param.SeeAlso(paramNameTagged),
+			param.SeeAlso(paramNameTagged, paramNameWhere),
+		)
+
+		ps.Add(paramNameWhere,
+			psetter.String[string]{
+				Value: &prog.whereStr,
+				Checks: []check.ValCk[string]{
+					check.StringLength[string](check.ValGT(0)),
+				},
+			},
+			"only show units whose tags satisfy the expression."+
+				" The expression is made of tag names combined with"+
+				" 'and', 'or' and 'not' ('&', '|' and '!' may also"+
+				" be used) and grouped with parentheses;"+
+				" 'not' binds more tightly than 'and' which binds"+
+				" more tightly than 'or'."+
+				" Tag names can be given in any case"+
+				" and need not be quoted even if they contain spaces."+
+				" For instance:"+
+				"\n\n"+
+				"(metric or SI) and not historic"+
+				"\n\n"+
+				"The '"+paramNameTagged+"' and"+
+				" '"+paramNameNotTagged+"' parameters are shorthand"+
+				" for 'and tag' and 'and not tag' and can be"+
+				" combined with this."+
+				"\n\n"+
+				"This should only be given when listing all the units"+
+				" for a single family.",
+			param.ValueName("tag-expression"),
+			param.SeeAlso(paramNameTagged, paramNameNotTagged),
 		)
 
 		detailsParam := ps.Add(paramNameShowDetails,
 			psetter.Bool{Value: &prog.showDetail},
 			"show details when listing."+
+				" When listing units this is the same as giving the"+
+				" '"+paramNameColumns+"' parameter with"+
+				" '"+dfltDetailColsStr()+"'."+
 				"\n\n"+
 				"This should not be given when"+
 				" showing details for a single unit.",
 			param.AltNames("show-detail", "l"),
+			param.SeeAlso(paramNameFormat, paramNameColumns),
 		)
 
 		noHdrParam := ps.Add(paramNameNoHeader,
@@ -92,18 +372,160 @@ func addParams(prog *prog) param.PSetOptFunc {
 			param.AltNames("no-hdr"),
 		)
 
+		ps.Add(paramNameFormat,
+			psetter.Enum[outputFormat]{
+				Value: &prog.format,
+				AllowedVals: psetter.AllowedVals[outputFormat]{
+					fmtText: "aligned columns of text",
+					fmtJSON: "JSON; a list of objects when listing" +
+						" and a single object when showing a single unit",
+					fmtCSV: "comma-separated values, with a header" +
+						" row unless the '" + paramNameNoHeader + "'" +
+						" parameter is given",
+					fmtMarkdown: "a Markdown table",
+				},
+			},
+			"the format in which to show the families or units."+
+				" All formats other than '"+string(fmtText)+"'"+
+				" show every field: the aliases, tags,"+
+				" conversion factor, pre- and post-conversion"+
+				" offsets and notes.",
+			param.AltNames("fmt"),
+			param.ValueName("format"),
+			param.SeeAlso(paramNameShowDetails, paramNameNoHeader),
+		)
+
 		ps.AddFinalCheck(func() error {
-			if unitParam.HasBeenSet() {
-				if !familyParam.HasBeenSet() {
-					return errors.New("if a unit name is given" +
-						" a family name must also be given")
+			if columnsParam.HasBeenSet() && detailsParam.HasBeenSet() {
+				return fmt.Errorf(
+					"the %q and %q parameters cannot both be given",
+					paramNameColumns, paramNameShowDetails)
+			}
+
+			if sortByParam.HasBeenSet() && orderParam.HasBeenSet() {
+				return fmt.Errorf(
+					"the %q and %q parameters cannot both be given",
+					paramNameSortBy, paramNameByName)
+			}
+
+			if prog.format != fmtText {
+				if relToParam.HasBeenSet() {
+					return fmt.Errorf("showing the units relative to"+
+						" another unit has no effect with the %q format",
+						prog.format)
+				}
+
+				if columnsParam.HasBeenSet() {
+					return fmt.Errorf("choosing the columns"+
+						" has no effect with the %q format"+
+						" which always shows every field",
+						prog.format)
+				}
+
+				if detailsParam.HasBeenSet() {
+					return fmt.Errorf("asking to see more detail"+
+						" has no effect with the %q format"+
+						" which always shows every field",
+						prog.format)
+				}
+
+				if noHdrParam.HasBeenSet() && prog.format != fmtCSV {
+					return fmt.Errorf("asking to not show headers"+
+						" has no effect with the %q format",
+						prog.format)
+				}
+			}
+
+			if lintParam.HasBeenSet() {
+				return prog.checkLintParams(
+					unitParam, searchParam, compareParam, summaryParam,
+					orderParam, sortByParam, columnsParam, detailsParam,
+					relToParam, minSizeParam, maxSizeParam,
+				)
+			}
+
+			if summaryParam.HasBeenSet() {
+				return prog.checkSummaryParams(
+					unitParam, searchParam, compareParam,
+					orderParam, sortByParam, columnsParam, detailsParam,
+					relToParam, minSizeParam, maxSizeParam,
+				)
+			}
+
+			if minSizeParam.HasBeenSet() || maxSizeParam.HasBeenSet() {
+				if !familyParam.HasBeenSet() ||
+					unitParam.HasBeenSet() || compareParam.HasBeenSet() {
+					return fmt.Errorf(
+						"the %q and %q parameters should only be given"+
+							" when listing the units in a family",
+						paramNameMinSize, paramNameMaxSize)
+				}
+
+				if err := prog.setSizeLimits(); err != nil {
+					return err
+				}
+			}
+
+			if relToParam.HasBeenSet() {
+				if !familyParam.HasBeenSet() ||
+					unitParam.HasBeenSet() || compareParam.HasBeenSet() {
+					return fmt.Errorf(
+						"the %q parameter should only be given"+
+							" when listing the units in a family",
+						paramNameRelativeTo)
+				}
+
+				u, err := convert.FindUnit(prog.family, prog.relativeTo)
+				if err != nil {
+					return fmt.Errorf("bad %q value: %w",
+						paramNameRelativeTo, err)
+				}
+
+				prog.relUnit = u
+			}
+
+			if compareParam.HasBeenSet() {
+				if err := prog.checkCompareParams(
+					unitParam, orderParam, detailsParam, searchParam,
+					sortByParam, columnsParam,
+				); err != nil {
+					return err
 				}
 
-				if orderParam.HasBeenSet() {
+				return prog.findCompareUnits()
+			}
+
+			if prog.searching() {
+				if unitParam.HasBeenSet() {
+					return fmt.Errorf(
+						"the %q and %q parameters cannot both be given",
+						paramNameSearch, paramNameUnit)
+				}
+
+				if err := prog.makeMatcher(); err != nil {
+					return err
+				}
+			} else if searchModeParam.HasBeenSet() ||
+				searchAliasesParam.HasBeenSet() ||
+				searchNotesParam.HasBeenSet() {
+				return fmt.Errorf(
+					"unless the %q parameter is given"+
+						" the %q, %q and %q parameters have no effect",
+					paramNameSearch, paramNameSearchMode,
+					paramNameSearchAliases, paramNameSearchNotes)
+			}
+
+			if unitParam.HasBeenSet() {
+				if orderParam.HasBeenSet() || sortByParam.HasBeenSet() {
 					return errors.New("specifying the order of units" +
 						" has no effect when showing a single unit")
 				}
 
+				if columnsParam.HasBeenSet() {
+					return errors.New("choosing the columns" +
+						" has no effect when showing a single unit")
+				}
+
 				if prog.hasTagConstraints() {
 					return errors.New(
 						"constraining the units to list by tag name" +
@@ -115,7 +537,7 @@ func addParams(prog *prog) param.PSetOptFunc {
 						" has no effect when showing a single unit")
 				}
 
-				if noHdrParam.HasBeenSet() {
+				if noHdrParam.HasBeenSet() && prog.format != fmtCSV {
 					return errors.New("asking to not show headers" +
 						" has no effect when showing a single unit")
 				}
@@ -123,21 +545,128 @@ func addParams(prog *prog) param.PSetOptFunc {
 				return nil
 			}
 
-			if !familyParam.HasBeenSet() {
-				if orderParam.HasBeenSet() {
+			if !familyParam.HasBeenSet() && !searchParam.HasBeenSet() {
+				if orderParam.HasBeenSet() || sortByParam.HasBeenSet() {
 					return errors.New("specifying the order of units" +
 						" only has an effect when listing units in a family")
 				}
 
+				if columnsParam.HasBeenSet() {
+					return errors.New("choosing the columns" +
+						" only has an effect when listing units in a family")
+				}
+
 				if prog.hasTagConstraints() {
 					return errors.New("constraining the units to list" +
 						" only has an effect when listing units in a family")
 				}
 			}
 
-			return prog.checkTagLists()
+			if err := prog.checkTagLists(); err != nil {
+				return err
+			}
+
+			if err := prog.setColumns(); err != nil {
+				return err
+			}
+
+			return prog.makeTagFilter()
 		})
 
 		return nil
 	}
 }
+
+// checkNoneSet returns an error if any of the conflicting parameters has
+// been given
+func checkNoneSet(name string, conflicts ...*param.ByName) error {
+	for _, p := range conflicts {
+		if p.HasBeenSet() {
+			return fmt.Errorf(
+				"the %q and %q parameters cannot both be given",
+				name, p.Name())
+		}
+	}
+
+	return nil
+}
+
+// checkCompareParams checks that the parameters given can be used when
+// comparing units. None of the conflicting parameters should have been
+// given.
+func (prog prog) checkCompareParams(conflicts ...*param.ByName) error {
+	if err := checkNoneSet(paramNameCompare, conflicts...); err != nil {
+		return err
+	}
+
+	if prog.hasTagConstraints() {
+		return errors.New("constraining the units by tag name" +
+			" has no effect when comparing units")
+	}
+
+	return nil
+}
+
+// checkLintParams checks that the parameters given can be used when
+// checking the unit definitions. None of the conflicting parameters should
+// have been given.
+func (prog prog) checkLintParams(conflicts ...*param.ByName) error {
+	if err := checkNoneSet(paramNameLint, conflicts...); err != nil {
+		return err
+	}
+
+	if prog.hasTagConstraints() {
+		return errors.New("constraining the units by tag name" +
+			" has no effect when checking the unit definitions")
+	}
+
+	return nil
+}
+
+// setSizeLimits sets the minimum and maximum sizes of the units to show,
+// in the base units of the family, from the quantities given
+func (prog *prog) setSizeLimits() error {
+	for _, limit := range []struct {
+		name string
+		qty  string
+		size *float64
+	}{
+		{paramNameMinSize, prog.minSizeStr, &prog.minSize},
+		{paramNameMaxSize, prog.maxSizeStr, &prog.maxSize},
+	} {
+		if limit.qty == "" {
+			continue
+		}
+
+		v, err := convert.ParseQuantity(prog.family, limit.qty)
+		if err != nil {
+			return fmt.Errorf("bad %q value: %w", limit.name, err)
+		}
+
+		*limit.size = convert.Size(v)
+	}
+
+	if prog.minSize > prog.maxSize {
+		return fmt.Errorf("the %q (%s) is larger than the %q (%s)",
+			paramNameMinSize, prog.minSizeStr,
+			paramNameMaxSize, prog.maxSizeStr)
+	}
+
+	return nil
+}
+
+// checkSummaryParams checks that the parameters given can be used when
+// summarising the families. None of the conflicting parameters should have
+// been given.
+func (prog prog) checkSummaryParams(conflicts ...*param.ByName) error {
+	if err := checkNoneSet(paramNameSummary, conflicts...); err != nil {
+		return err
+	}
+
+	if prog.hasTagConstraints() {
+		return errors.New("constraining the units by tag name" +
+			" has no effect when summarising the families")
+	}
+
+	return nil
+}
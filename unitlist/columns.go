@@ -0,0 +1,402 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/nickwells/col.mod/v6/col"
+	"github.com/nickwells/col.mod/v6/colfmt"
+	"github.com/nickwells/english.mod/english"
+	"github.com/nickwells/param.mod/v7/psetter"
+	"github.com/nickwells/units.mod/v2/units"
+	"github.com/nickwells/unittools/convert"
+)
+
+// colName names a column which can be shown when listing units
+type colName string
+
+const (
+	colNameName       colName = "name"
+	colNameAbbrev     colName = "abbreviation"
+	colNamePlural     colName = "plural"
+	colNameAliases    colName = "aliases"
+	colNameTags       colName = "tags"
+	colNameFactor     colName = "factor"
+	colNameInverse    colName = "inverse"
+	colNamePreAdd     colName = "pre-add"
+	colNamePostAdd    colName = "post-add"
+	colNameFormula    colName = "formula"
+	colNameNotes      colName = "notes"
+	colNameBaseMarker colName = "base-marker"
+)
+
+// colWidthSep separates the column name from its width
+const colWidthSep = ":"
+
+// colDef describes a column which can be shown when listing units
+type colDef struct {
+	desc  string
+	dfltW int
+	// makeCol returns the column with the given width
+	makeCol func(prog prog, w int) *col.Col
+	// val returns the value to show in the column for the unit
+	val func(prog prog, u units.Unit) any
+	// sortVal, if set, returns the value to sort on in place of the value
+	// shown
+	sortVal func(u units.Unit) any
+}
+
+// colSpec records a column chosen to be shown and its width
+type colSpec struct {
+	name colName
+	w    int
+}
+
+// makeWrappedCol returns a function making a column of wrapped strings
+func makeWrappedCol(hdrs ...string) func(prog, int) *col.Col {
+	return func(_ prog, w int) *col.Col {
+		return col.New(&colfmt.WrappedString{W: w}, hdrs...)
+	}
+}
+
+// makeFloatCol returns a function making a column of numbers
+func makeFloatCol(hdrs ...string) func(prog, int) *col.Col {
+	return func(_ prog, w int) *col.Col {
+		return col.New(&colfmt.Float{
+			W:                        w,
+			Prec:                     9, //nolint:mnd
+			TrimTrailingZeroes:       true,
+			ReformatOutOfBoundValues: true,
+		}, hdrs...)
+	}
+}
+
+// colDefs holds the definitions of the columns which can be shown
+//
+//nolint:mnd
+var colDefs = map[colName]colDef{
+	colNameName: {
+		desc:    "the name by which the unit is chosen",
+		dfltW:   20,
+		makeCol: makeWrappedCol("Unit Name"),
+		val:     func(_ prog, u units.Unit) any { return u.ID() },
+	},
+	colNameAbbrev: {
+		desc:    "the abbreviated name of the unit",
+		dfltW:   10,
+		makeCol: makeWrappedCol("Abbreviation"),
+		val:     func(_ prog, u units.Unit) any { return u.Abbrev() },
+	},
+	colNamePlural: {
+		desc:    "the plural form of the full name of the unit",
+		dfltW:   20,
+		makeCol: makeWrappedCol("Plural"),
+		val:     func(_ prog, u units.Unit) any { return u.NamePlural() },
+	},
+	colNameAliases: {
+		desc:    "the alternative names of the unit",
+		dfltW:   20,
+		makeCol: makeWrappedCol("Aliases"),
+		val: func(_ prog, u units.Unit) any {
+			return strings.Join(slices.Sorted(maps.Keys(u.Aliases())), "\n")
+		},
+	},
+	colNameTags: {
+		desc:    "the tags of the unit",
+		dfltW:   20,
+		makeCol: makeWrappedCol("Tags"),
+		val:     func(_ prog, u units.Unit) any { return getUnitTags(u) },
+	},
+	colNameFactor: {
+		desc: "the factor converting the unit into the base unit." +
+			" If a reference unit has been given this is the number" +
+			" of reference units in one of the unit or, if the" +
+			" conversion is not a simple multiplication, the formula" +
+			" converting the unit into the reference unit",
+		dfltW: 20,
+		makeCol: func(prog prog, w int) *col.Col {
+			if prog.relativeTo == "" {
+				return makeFloatCol("Conversion", "Factor")(prog, w)
+			}
+
+			return makeWrappedCol("Conversion to", prog.refUnitName())(prog, w)
+		},
+		val: func(prog prog, u units.Unit) any {
+			if prog.relativeTo == "" {
+				return u.ConvFactor()
+			}
+
+			return relConversion(u, prog.refUnit(u))
+		},
+		sortVal: func(u units.Unit) any { return u.ConvFactor() },
+	},
+	colNameInverse: {
+		desc: "the number of units in one of the reference unit, or" +
+			" of the base unit if no reference unit has been given," +
+			" or, if the conversion is not a simple multiplication," +
+			" the formula converting the reference unit into the unit",
+		dfltW: 20,
+		makeCol: func(prog prog, w int) *col.Col {
+			return makeWrappedCol("Conversion from",
+				prog.refUnitName())(prog, w)
+		},
+		val: func(prog prog, u units.Unit) any {
+			return relConversion(prog.refUnit(u), u)
+		},
+		sortVal: func(u units.Unit) any { return 1 / u.ConvFactor() },
+	},
+	colNamePreAdd: {
+		desc: "the value added before converting" +
+			" the unit into the base unit",
+		dfltW:   12,
+		makeCol: makeFloatCol("Pre-Add"),
+		val:     func(_ prog, u units.Unit) any { return u.ConvPreAdd() },
+	},
+	colNamePostAdd: {
+		desc: "the value added after converting" +
+			" the unit into the base unit",
+		dfltW:   12,
+		makeCol: makeFloatCol("Post-Add"),
+		val:     func(_ prog, u units.Unit) any { return u.ConvPostAdd() },
+	},
+	colNameFormula: {
+		desc: "the formula converting a value in the base unit" +
+			" into the unit",
+		dfltW:   30,
+		makeCol: makeWrappedCol("Conversion", "Formula"),
+		val: func(_ prog, u units.Unit) any {
+			return strings.TrimSpace(u.ConversionFormula())
+		},
+	},
+	colNameNotes: {
+		desc: "the notes describing the unit, together with its" +
+			" aliases and a warning if the conversion is not a simple" +
+			" multiplication, unless these are shown in other columns",
+		dfltW:   40,
+		makeCol: makeWrappedCol("Notes"),
+		val:     func(prog prog, u units.Unit) any { return prog.unitNotes(u) },
+	},
+	colNameBaseMarker: {
+		desc:  "'>>>' if the unit is the base unit of the family",
+		dfltW: 0,
+		makeCol: func(_ prog, w int) *col.Col {
+			return col.New(&colfmt.String{W: w}, "Base", "Unit")
+		},
+		val: func(_ prog, u units.Unit) any {
+			if u.ID() == u.Family().BaseUnitName() {
+				return ">>>"
+			}
+
+			return ""
+		},
+		sortVal: func(u units.Unit) any {
+			if u.ID() == u.Family().BaseUnitName() {
+				return 0
+			}
+
+			return 1
+		},
+	},
+}
+
+// dfltDetailCols gives the columns shown when asked to show details
+var dfltDetailCols = []colName{
+	colNameBaseMarker, colNameName, colNameTags, colNameFactor, colNameNotes,
+}
+
+// dfltDetailColsStr returns the default detail columns as they would be
+// given to the columns parameter
+func dfltDetailColsStr() string {
+	names := make([]string, 0, len(dfltDetailCols))
+	for _, cn := range dfltDetailCols {
+		names = append(names, string(cn))
+	}
+
+	return strings.Join(names, ",")
+}
+
+// colNames returns the sorted names of the columns which can be shown
+func colNames() []string {
+	names := make([]string, 0, len(colDefs))
+	for cn := range colDefs {
+		names = append(names, string(cn))
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
+// colAllowedVals returns the allowed values for the column names
+func colAllowedVals() psetter.AllowedVals[colName] {
+	av := psetter.AllowedVals[colName]{}
+	for cn, cd := range colDefs {
+		av[cn] = cd.desc
+	}
+
+	return av
+}
+
+// parseColSpec parses the column specification, which is a column name
+// optionally followed by a width
+func parseColSpec(s string) (colSpec, error) {
+	name, wStr, hasW := strings.Cut(s, colWidthSep)
+
+	cn := colName(strings.TrimSpace(name))
+
+	cd, ok := colDefs[cn]
+	if !ok {
+		return colSpec{}, fmt.Errorf(
+			"unknown column %q, the valid columns are: %s",
+			cn, english.JoinQuoted(colNames(), ", ", " and "))
+	}
+
+	cs := colSpec{name: cn, w: cd.dfltW}
+
+	if hasW {
+		w, err := strconv.Atoi(strings.TrimSpace(wStr))
+		if err != nil || w <= 0 {
+			return colSpec{}, fmt.Errorf(
+				"bad width for column %q: %q, it must be a whole number"+
+					" greater than zero",
+				cn, wStr)
+		}
+
+		cs.w = w
+	}
+
+	return cs, nil
+}
+
+// detailCols returns the columns shown when asked to show details. If a
+// reference unit has been given the inverse conversion is shown after the
+// conversion factor.
+func (prog prog) detailCols() []colName {
+	if prog.relativeTo == "" {
+		return dfltDetailCols
+	}
+
+	cols := []colName{}
+
+	for _, cn := range dfltDetailCols {
+		cols = append(cols, cn)
+		if cn == colNameFactor {
+			cols = append(cols, colNameInverse)
+		}
+	}
+
+	return cols
+}
+
+// setColumns sets the columns to show from the column specifications. If
+// none are given but details are to be shown, or a reference unit has been
+// given, the default detail columns are used.
+func (prog *prog) setColumns() error {
+	if len(prog.columnSpecs) == 0 &&
+		(prog.showDetail || prog.relativeTo != "") {
+		for _, cn := range prog.detailCols() {
+			prog.columns = append(prog.columns,
+				colSpec{name: cn, w: colDefs[cn].dfltW})
+		}
+
+		return nil
+	}
+
+	for _, s := range prog.columnSpecs {
+		cs, err := parseColSpec(s)
+		if err != nil {
+			return err
+		}
+
+		if prog.showingCol(cs.name) {
+			return fmt.Errorf("the column %q has been given more than once",
+				cs.name)
+		}
+
+		prog.columns = append(prog.columns, cs)
+	}
+
+	return nil
+}
+
+// showingCol returns true if any of the named columns is to be shown
+func (prog prog) showingCol(names ...colName) bool {
+	return slices.ContainsFunc(prog.columns, func(cs colSpec) bool {
+		return slices.Contains(names, cs.name)
+	})
+}
+
+// unitNotes builds the notes column value for the given unit. The aliases
+// and the warning that the conversion is not a simple multiplication are
+// only added if they are not being shown in other columns.
+func (prog prog) unitNotes(u units.Unit) string {
+	var notes strings.Builder
+	notes.WriteString(u.Notes())
+
+	aliases := u.Aliases()
+	if len(aliases) > 0 && !prog.showingCol(colNameAliases) {
+		aliasNames := slices.Sorted(maps.Keys(aliases))
+
+		notes.WriteString("\n\nAliases:")
+
+		for _, aName := range aliasNames {
+			notes.WriteString("\n    ")
+			notes.WriteString(aName)
+		}
+	}
+
+	formulaCols := []colName{
+		colNameFormula, colNamePreAdd, colNamePostAdd, colNameInverse,
+	}
+	if prog.relativeTo != "" {
+		formulaCols = append(formulaCols, colNameFactor)
+	}
+
+	if convert.HasOffset(u) && !prog.showingCol(formulaCols...) {
+		notes.WriteString("\n\n" +
+			"The conversion is not a simple multiplication," +
+			" show the unit details for a full explanation.")
+	}
+
+	return notes.String()
+}
+
+// compareColVals compares the values of a column, numerically if they are
+// numbers and as strings otherwise
+func compareColVals(a, b any) int {
+	switch av := a.(type) {
+	case float64:
+		if bv, ok := b.(float64); ok {
+			return cmp.Compare(av, bv)
+		}
+	case int:
+		if bv, ok := b.(int); ok {
+			return cmp.Compare(av, bv)
+		}
+	}
+
+	return cmp.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// sortUnits sorts the units by the value of the sort column. The sort is
+// stable so units with the same value keep their order.
+func (prog prog) sortUnits(us []units.Unit) {
+	if prog.sortBy == "" {
+		return
+	}
+
+	cd := colDefs[prog.sortBy]
+
+	key := cd.sortVal
+	if key == nil {
+		key = func(u units.Unit) any { return cd.val(prog, u) }
+	}
+
+	slices.SortStableFunc(us, func(a, b units.Unit) int {
+		return compareColVals(key(a), key(b))
+	})
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestParseColSpec(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		s      string
+		expCol colSpec
+	}{
+		{
+			ID:     testhelper.MkID("default width"),
+			s:      "notes",
+			expCol: colSpec{name: colNameNotes, w: colDefs[colNameNotes].dfltW},
+		},
+		{
+			ID:     testhelper.MkID("with width"),
+			s:      "tags:12",
+			expCol: colSpec{name: colNameTags, w: 12},
+		},
+		{
+			ID:     testhelper.MkID("unknown column"),
+			ExpErr: testhelper.MkExpErr(`unknown column "nmae"`),
+			s:      "nmae",
+		},
+		{
+			ID:     testhelper.MkID("bad width"),
+			ExpErr: testhelper.MkExpErr(`bad width for column "tags": "x"`),
+			s:      "tags:x",
+		},
+		{
+			ID:     testhelper.MkID("zero width"),
+			ExpErr: testhelper.MkExpErr(`bad width for column "tags": "0"`),
+			s:      "tags:0",
+		},
+	}
+
+	for _, tc := range testCases {
+		cs, err := parseColSpec(tc.s)
+		if testhelper.CheckExpErr(t, err, tc) && err == nil {
+			testhelper.DiffString(t, tc.IDStr(), "name",
+				string(cs.name), string(tc.expCol.name))
+			testhelper.DiffInt(t, tc.IDStr(), "width", cs.w, tc.expCol.w)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"github.com/nickwells/col.mod/v6/col"
+	"github.com/nickwells/col.mod/v6/colfmt"
+	"github.com/nickwells/units.mod/v2/units"
+	"github.com/nickwells/unittools/convert"
+)
+
+// compareVal records how many of a unit there are in the compared unit
+type compareVal struct {
+	Unit  string  `json:"unit"`
+	Value float64 `json:"value"`
+}
+
+// compareInfo holds the details of a compared unit for the JSON format
+type compareInfo struct {
+	ID     string       `json:"id"`
+	Family string       `json:"family"`
+	Tags   []string     `json:"tags"`
+	Notes  string       `json:"notes"`
+	Equals []compareVal `json:"equals"`
+}
+
+// findCompareUnits finds the units to be compared. If no family has been
+// given then the first family having all the units is used.
+func (prog *prog) findCompareUnits() error {
+	first, rest, err := convert.FindUnits(
+		prog.family, prog.compareNames[0], prog.compareNames[1:])
+	if err != nil {
+		return err
+	}
+
+	prog.compareUnits = append([]units.Unit{first}, rest...)
+
+	return nil
+}
+
+// unitsIn returns how many of the second unit there are in one of the
+// first. Only the conversion factors are used so, for units with offsets
+// such as temperature scales, this compares the sizes of the units.
+func unitsIn(u, other units.Unit) float64 {
+	vu, err := convert.ConvertDelta(units.ValUnit{V: 1, U: u}, other)
+	if err != nil {
+		return 0
+	}
+
+	return vu.V
+}
+
+// makeCompareRpt generates the report comparing the units
+//
+//nolint:mnd
+func (prog prog) makeCompareRpt() *col.Report {
+	hdr := col.NewHeaderOrPanic()
+	if prog.noHeader {
+		hdr = col.NewHeaderOrPanic(col.HdrOptDontPrint)
+	}
+
+	maxW := 0
+	for _, u := range prog.compareUnits {
+		maxW = max(len(u.ID()), maxW)
+	}
+
+	cols := []*col.Col{col.New(&colfmt.String{W: maxW}, "One", "Unit")}
+
+	for _, u := range prog.compareUnits {
+		cols = append(cols,
+			col.New(&colfmt.Float{
+				W:                        max(12, len(u.ID())),
+				Prec:                     6,
+				ReformatOutOfBoundValues: true,
+			}, "Is this many", u.ID()))
+	}
+
+	cols = append(cols,
+		col.New(&colfmt.WrappedString{W: 20}, "Tags"),
+		col.New(&colfmt.WrappedString{W: 40}, "Notes"),
+	)
+
+	return col.NewReportOrPanic(hdr, prog.stdout, cols[0], cols[1:]...)
+}
+
+// compareUnitsHdr returns the column headings for the comparison in the
+// CSV and Markdown formats
+func (prog prog) compareUnitsHdr() []string {
+	hdr := []string{"Unit"}
+	for _, u := range prog.compareUnits {
+		hdr = append(hdr, u.ID())
+	}
+
+	return append(hdr, "Tags", "Notes")
+}
+
+// showComparison reports how many of each unit there are in each of the
+// other units
+func (prog *prog) showComparison() {
+	infos := make([]compareInfo, 0, len(prog.compareUnits))
+
+	for _, u := range prog.compareUnits {
+		ui := makeUnitInfo(u)
+		ci := compareInfo{
+			ID:     ui.ID,
+			Family: ui.Family,
+			Tags:   ui.Tags,
+			Notes:  ui.Notes,
+		}
+
+		for _, other := range prog.compareUnits {
+			ci.Equals = append(ci.Equals,
+				compareVal{Unit: other.ID(), Value: unitsIn(u, other)})
+		}
+
+		infos = append(infos, ci)
+	}
+
+	switch prog.format {
+	case fmtJSON:
+		prog.writeJSON(infos)
+	case fmtCSV, fmtMarkdown:
+		rows := make([][]string, 0, len(infos))
+
+		for _, ci := range infos {
+			row := []string{ci.ID}
+			for _, cv := range ci.Equals {
+				row = append(row, formatFloat(cv.Value))
+			}
+
+			rows = append(rows, append(row, joinList(ci.Tags), ci.Notes))
+		}
+
+		prog.writeTable(prog.compareUnitsHdr(), rows)
+	default:
+		rpt := prog.makeCompareRpt()
+
+		for i, ci := range infos {
+			vals := []any{ci.ID}
+			for _, cv := range ci.Equals {
+				vals = append(vals, cv.Value)
+			}
+
+			vals = append(vals, getUnitTags(prog.compareUnits[i]), ci.Notes)
+
+			if err := rpt.PrintRow(vals...); err != nil {
+				reportWriteErr(err)
+			}
+		}
+	}
+}
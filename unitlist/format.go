@@ -0,0 +1,305 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/nickwells/units.mod/v2/units"
+)
+
+// outputFormat is the format in which the families and units are shown
+type outputFormat string
+
+const (
+	fmtText     outputFormat = "text"
+	fmtJSON     outputFormat = "json"
+	fmtCSV      outputFormat = "csv"
+	fmtMarkdown outputFormat = "markdown"
+)
+
+// listSep separates the entries in fields holding a list of values in the
+// CSV and Markdown formats
+const listSep = ", "
+
+// joinList joins the entries of a list for the CSV and Markdown formats
+func joinList(s []string) string {
+	return strings.Join(s, listSep)
+}
+
+// familyInfo holds the details of a unit family for the structured output
+// formats
+type familyInfo struct {
+	Name        string   `json:"name"`
+	Aliases     []string `json:"aliases"`
+	Description string   `json:"description"`
+	BaseUnit    string   `json:"baseUnit"`
+}
+
+// aliasInfo holds the details of a unit alias for the structured output
+// formats
+type aliasInfo struct {
+	Name  string `json:"name"`
+	Notes string `json:"notes"`
+}
+
+// unitInfo holds the details of a unit for the structured output formats
+type unitInfo struct {
+	ID          string      `json:"id"`
+	Family      string      `json:"family"`
+	Name        string      `json:"name"`
+	NamePlural  string      `json:"namePlural"`
+	Abbrev      string      `json:"abbrev"`
+	IsBaseUnit  bool        `json:"isBaseUnit"`
+	Aliases     []aliasInfo `json:"aliases"`
+	Tags        []string    `json:"tags"`
+	ConvFactor  float64     `json:"convFactor"`
+	ConvPreAdd  float64     `json:"convPreAdd"`
+	ConvPostAdd float64     `json:"convPostAdd"`
+	Notes       string      `json:"notes"`
+}
+
+// makeFamilyInfo returns the details of the named family
+func makeFamilyInfo(fName string) familyInfo {
+	f := units.GetFamilyOrPanic(fName)
+
+	aliases := f.FamilyAliases()
+	if aliases == nil {
+		aliases = []string{}
+	}
+
+	return familyInfo{
+		Name:        fName,
+		Aliases:     aliases,
+		Description: f.Description(),
+		BaseUnit:    f.BaseUnitName(),
+	}
+}
+
+// makeUnitInfo returns the details of the unit
+func makeUnitInfo(u units.Unit) unitInfo {
+	aliases := u.Aliases()
+
+	ui := unitInfo{
+		ID:          u.ID(),
+		Family:      u.Family().Name(),
+		Name:        u.Name(),
+		NamePlural:  u.NamePlural(),
+		Abbrev:      u.Abbrev(),
+		IsBaseUnit:  u.ID() == u.Family().BaseUnitName(),
+		Aliases:     make([]aliasInfo, 0, len(aliases)),
+		Tags:        make([]string, 0, len(u.Tags())),
+		ConvFactor:  u.ConvFactor(),
+		ConvPreAdd:  u.ConvPreAdd(),
+		ConvPostAdd: u.ConvPostAdd(),
+		Notes:       u.Notes(),
+	}
+
+	for _, aName := range slices.Sorted(maps.Keys(aliases)) {
+		ui.Aliases = append(ui.Aliases,
+			aliasInfo{Name: aName, Notes: aliases[aName]})
+	}
+
+	for _, t := range u.Tags() {
+		ui.Tags = append(ui.Tags, string(t))
+	}
+
+	return ui
+}
+
+// aliasNames returns the names of the aliases
+func (ui unitInfo) aliasNames() []string {
+	names := make([]string, 0, len(ui.Aliases))
+	for _, a := range ui.Aliases {
+		names = append(names, a.Name)
+	}
+
+	return names
+}
+
+// familyTableHdr returns the column headings for the family list in the
+// CSV and Markdown formats
+func familyTableHdr() []string {
+	return []string{"Name", "Aliases", "Description", "Base Unit"}
+}
+
+// tableRow returns the family details as a row in the CSV and Markdown
+// formats
+func (fi familyInfo) tableRow() []string {
+	return []string{
+		fi.Name,
+		joinList(fi.Aliases),
+		fi.Description,
+		fi.BaseUnit,
+	}
+}
+
+// unitTableHdr returns the column headings for the unit list in the CSV and
+// Markdown formats
+func unitTableHdr() []string {
+	return []string{
+		"ID", "Family", "Name", "Plural", "Abbreviation", "Base Unit",
+		"Aliases", "Tags",
+		"Conversion Factor", "Pre-Add", "Post-Add",
+		"Notes",
+	}
+}
+
+// tableRow returns the unit details as a row in the CSV and Markdown
+// formats
+func (ui unitInfo) tableRow() []string {
+	return []string{
+		ui.ID,
+		ui.Family,
+		ui.Name,
+		ui.NamePlural,
+		ui.Abbrev,
+		strconv.FormatBool(ui.IsBaseUnit),
+		joinList(ui.aliasNames()),
+		joinList(ui.Tags),
+		formatFloat(ui.ConvFactor),
+		formatFloat(ui.ConvPreAdd),
+		formatFloat(ui.ConvPostAdd),
+		ui.Notes,
+	}
+}
+
+// formatFloat formats the value using the fewest digits needed to
+// represent it exactly
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// writeFamilies writes the details of the named families in the chosen
+// structured format
+func (prog *prog) writeFamilies(fNames []string) {
+	infos := make([]familyInfo, 0, len(fNames))
+	for _, fName := range fNames {
+		infos = append(infos, makeFamilyInfo(fName))
+	}
+
+	if prog.format == fmtJSON {
+		prog.writeJSON(infos)
+		return
+	}
+
+	rows := make([][]string, 0, len(infos))
+	for _, fi := range infos {
+		rows = append(rows, fi.tableRow())
+	}
+
+	prog.writeTable(familyTableHdr(), rows)
+}
+
+// writeUnits writes the details of the units in the chosen structured
+// format
+func (prog *prog) writeUnits(us []units.Unit) {
+	infos := make([]unitInfo, 0, len(us))
+	for _, u := range us {
+		infos = append(infos, makeUnitInfo(u))
+	}
+
+	if prog.format == fmtJSON {
+		prog.writeJSON(infos)
+		return
+	}
+
+	rows := make([][]string, 0, len(infos))
+	for _, ui := range infos {
+		rows = append(rows, ui.tableRow())
+	}
+
+	prog.writeTable(unitTableHdr(), rows)
+}
+
+// writeUnit writes the details of the unit in the chosen structured
+// format. In the JSON format this is a single object rather than a list.
+func (prog *prog) writeUnit(u units.Unit) {
+	if prog.format == fmtJSON {
+		prog.writeJSON(makeUnitInfo(u))
+		return
+	}
+
+	prog.writeUnits([]units.Unit{u})
+}
+
+// writeJSON writes the value as indented JSON
+func (prog *prog) writeJSON(v any) {
+	enc := json.NewEncoder(prog.stdout)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(v); err != nil {
+		reportWriteErr(err)
+	}
+}
+
+// writeTable writes the rows in the CSV or Markdown format. The header is
+// not written in the CSV format if the noHeader flag is set.
+func (prog *prog) writeTable(hdr []string, rows [][]string) {
+	if prog.format == fmtMarkdown {
+		prog.writeMarkdown(hdr, rows)
+		return
+	}
+
+	w := csv.NewWriter(prog.stdout)
+
+	if !prog.noHeader {
+		_ = w.Write(hdr)
+	}
+
+	_ = w.WriteAll(rows)
+
+	if err := w.Error(); err != nil {
+		reportWriteErr(err)
+	}
+}
+
+// mdCell returns the value escaped so that it can be used as a cell in a
+// Markdown table
+func mdCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	s = strings.ReplaceAll(s, "\n", "<br>")
+
+	return s
+}
+
+// writeMarkdown writes the rows as a Markdown table
+func (prog *prog) writeMarkdown(hdr []string, rows [][]string) {
+	var md strings.Builder
+
+	writeRow := func(cells []string) {
+		md.WriteString("|")
+
+		for _, c := range cells {
+			md.WriteString(" " + mdCell(c) + " |")
+		}
+
+		md.WriteString("\n")
+	}
+
+	writeRow(hdr)
+
+	md.WriteString("|")
+	md.WriteString(strings.Repeat(" --- |", len(hdr)))
+	md.WriteString("\n")
+
+	for _, r := range rows {
+		writeRow(r)
+	}
+
+	if _, err := fmt.Fprint(prog.stdout, md.String()); err != nil {
+		reportWriteErr(err)
+	}
+}
+
+// reportWriteErr reports the error found while writing the output and
+// exits
+func reportWriteErr(err error) {
+	fmt.Fprintln(os.Stderr, "Error found while writing the output:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"sort"
+
+	"github.com/nickwells/col.mod/v6/col"
+	"github.com/nickwells/col.mod/v6/colfmt"
+	"github.com/nickwells/english.mod/english"
+	"github.com/nickwells/units.mod/v2/units"
+)
+
+// These name the checks made on the unit definitions
+const (
+	lintMissingUnit  = "missing-unit"
+	lintDupAlias     = "duplicate-alias"
+	lintAliasIsUnit  = "alias-shadows-unit"
+	lintDupFactor    = "duplicate-factor"
+	lintNoNotes      = "missing-notes"
+	lintNoPlural     = "missing-plural"
+	lintUnknownTag   = "unknown-tag"
+	lintAbbreviation = "abbreviation"
+)
+
+// lintFactorTolerance is the relative difference between two conversion
+// factors below which they are taken to be the same
+const lintFactorTolerance = 1e-9
+
+// lintProblem records a problem found in the definition of a unit
+type lintProblem struct {
+	Family  string `json:"family"`
+	Unit    string `json:"unit"`
+	Check   string `json:"check"`
+	Problem string `json:"problem"`
+}
+
+// tableRow returns the problem as a row in the CSV and Markdown formats
+func (lp lintProblem) tableRow() []string {
+	return []string{lp.Family, lp.Unit, lp.Check, lp.Problem}
+}
+
+// lintTableHdr returns the column headings for the problem report in the
+// CSV and Markdown formats
+func lintTableHdr() []string {
+	return []string{"Family", "Unit", "Check", "Problem"}
+}
+
+// familyLinter collects the problems found in the units of a family
+type familyLinter struct {
+	f        *units.Family
+	us       []units.Unit
+	problems []lintProblem
+}
+
+// addProblem records a problem with the unit
+func (fl *familyLinter) addProblem(u units.Unit, check, format string,
+	args ...any,
+) {
+	fl.problems = append(fl.problems, lintProblem{
+		Family:  fl.f.Name(),
+		Unit:    u.ID(),
+		Check:   check,
+		Problem: fmt.Sprintf(format, args...),
+	})
+}
+
+// unitIDs returns the set of the IDs of the units in the family
+func (fl *familyLinter) unitIDs() map[string]bool {
+	ids := map[string]bool{}
+	for _, u := range fl.us {
+		ids[u.ID()] = true
+	}
+
+	return ids
+}
+
+// checkAliases reports aliases given for more than one unit and aliases
+// which are the ID of another unit
+func (fl *familyLinter) checkAliases() {
+	ids := fl.unitIDs()
+	aliasOwner := map[string]units.Unit{}
+
+	for _, u := range fl.us {
+		for _, alias := range slices.Sorted(maps.Keys(u.Aliases())) {
+			if owner, ok := aliasOwner[alias]; ok {
+				fl.addProblem(u, lintDupAlias,
+					"the alias %q is also an alias of %q", alias, owner.ID())
+			} else {
+				aliasOwner[alias] = u
+			}
+
+			if alias != u.ID() && ids[alias] {
+				fl.addProblem(u, lintAliasIsUnit,
+					"the alias %q is the name of another unit", alias)
+			}
+		}
+	}
+}
+
+// nearFactor returns true if the conversion factors of the two units are
+// the same, allowing for rounding errors
+func nearFactor(a, b units.Unit) bool {
+	fa, fb := a.ConvFactor(), b.ConvFactor()
+
+	return math.Abs(fa-fb) <=
+		lintFactorTolerance*max(math.Abs(fa), math.Abs(fb))
+}
+
+// checkFactors reports units which convert to the base unit in the same
+// way as another unit and so may be duplicates
+func (fl *familyLinter) checkFactors() {
+	us := slices.Clone(fl.us)
+	slices.SortStableFunc(us, func(a, b units.Unit) int {
+		return cmp.Compare(a.ConvFactor(), b.ConvFactor())
+	})
+
+	for i, u := range us {
+		for _, other := range us[i+1:] {
+			if !nearFactor(u, other) {
+				break
+			}
+
+			if u.ConvPreAdd() == other.ConvPreAdd() &&
+				u.ConvPostAdd() == other.ConvPostAdd() {
+				fl.addProblem(other, lintDupFactor,
+					"the conversion is the same as for %q", u.ID())
+			}
+		}
+	}
+}
+
+// checkDescriptions reports units without notes or a plural name
+func (fl *familyLinter) checkDescriptions() {
+	for _, u := range fl.us {
+		if u.Notes() == "" {
+			fl.addProblem(u, lintNoNotes, "there are no notes")
+		}
+
+		if u.NamePlural() == "" {
+			fl.addProblem(u, lintNoPlural, "there is no plural name")
+		}
+	}
+}
+
+// checkTags reports units having tags which are not known
+func (fl *familyLinter) checkTags() {
+	known := map[units.Tag]bool{}
+	for _, tn := range units.GetTagNames() {
+		known[units.Tag(tn)] = true
+	}
+
+	for _, u := range fl.us {
+		for _, t := range u.Tags() {
+			if !known[t] {
+				fl.addProblem(u, lintUnknownTag, "the tag %q is not known", t)
+			}
+		}
+	}
+}
+
+// checkAbbreviations reports units without an abbreviation, units whose
+// abbreviation is shared with other units and units whose abbreviation is
+// the name of another unit. A shared abbreviation is reported for each of
+// the units sharing it since it cannot be known which of them is wrong.
+func (fl *familyLinter) checkAbbreviations() {
+	ids := fl.unitIDs()
+	abbrevUsers := map[string][]string{}
+
+	for _, u := range fl.us {
+		if abbrev := u.Abbrev(); abbrev != "" {
+			abbrevUsers[abbrev] = append(abbrevUsers[abbrev], u.ID())
+		}
+	}
+
+	for _, u := range fl.us {
+		abbrev := u.Abbrev()
+		if abbrev == "" {
+			fl.addProblem(u, lintAbbreviation, "there is no abbreviation")
+			continue
+		}
+
+		if users := abbrevUsers[abbrev]; len(users) > 1 {
+			others := slices.DeleteFunc(slices.Clone(users),
+				func(id string) bool { return id == u.ID() })
+			fl.addProblem(u, lintAbbreviation,
+				"the abbreviation %q is shared with %s",
+				abbrev, english.JoinQuoted(others, ", ", " and "))
+		}
+
+		if abbrev != u.ID() && ids[abbrev] {
+			fl.addProblem(u, lintAbbreviation,
+				"the abbreviation %q is the name of another unit", abbrev)
+		}
+	}
+}
+
+// lintFamily checks the definitions of the units in the family and returns
+// the problems found
+func lintFamily(f *units.Family) []lintProblem {
+	unitIDs := f.GetUnitNames()
+	sort.Strings(unitIDs)
+
+	fl := &familyLinter{f: f}
+
+	for _, uName := range unitIDs {
+		u, err := f.GetUnit(uName)
+		if err != nil {
+			fl.problems = append(fl.problems, lintProblem{
+				Family:  f.Name(),
+				Unit:    uName,
+				Check:   lintMissingUnit,
+				Problem: err.Error(),
+			})
+
+			continue
+		}
+
+		fl.us = append(fl.us, u)
+	}
+
+	fl.checkAliases()
+	fl.checkFactors()
+	fl.checkDescriptions()
+	fl.checkTags()
+	fl.checkAbbreviations()
+
+	slices.SortStableFunc(fl.problems, func(a, b lintProblem) int {
+		return cmp.Compare(a.Unit, b.Unit)
+	})
+
+	return fl.problems
+}
+
+// makeLintRpt generates the report of the problems found
+//
+//nolint:mnd
+func (prog prog) makeLintRpt(problems []lintProblem) *col.Report {
+	hdr := col.NewHeaderOrPanic()
+	if prog.noHeader {
+		hdr = col.NewHeaderOrPanic(col.HdrOptDontPrint)
+	}
+
+	familyW, checkW := 0, 0
+	for _, lp := range problems {
+		familyW = max(len(lp.Family), familyW)
+		checkW = max(len(lp.Check), checkW)
+	}
+
+	return col.NewReportOrPanic(hdr, prog.stdout,
+		col.New(&colfmt.String{W: familyW}, "Family"),
+		col.New(&colfmt.WrappedString{W: 20}, "Unit"),
+		col.New(&colfmt.String{W: checkW}, "Check"),
+		col.New(&colfmt.WrappedString{W: 50}, "Problem"),
+	)
+}
+
+// lintUnits checks the definitions of the units in every family, or just
+// in the given family, and reports any problems found. The exit status is
+// set if there are any problems.
+func (prog *prog) lintUnits() {
+	fNames := units.GetFamilyNames()
+	sort.Strings(fNames)
+
+	if prog.family != nil {
+		fNames = []string{prog.family.Name()}
+	}
+
+	problems := []lintProblem{}
+	for _, fName := range fNames {
+		problems = append(problems,
+			lintFamily(units.GetFamilyOrPanic(fName))...)
+	}
+
+	if len(problems) > 0 {
+		prog.exitStatus = 1
+	}
+
+	switch prog.format {
+	case fmtJSON:
+		prog.writeJSON(problems)
+	case fmtCSV, fmtMarkdown:
+		rows := make([][]string, 0, len(problems))
+		for _, lp := range problems {
+			rows = append(rows, lp.tableRow())
+		}
+
+		prog.writeTable(lintTableHdr(), rows)
+	default:
+		if len(problems) == 0 {
+			fmt.Fprintln(prog.stdout, "no problems were found")
+			return
+		}
+
+		rpt := prog.makeLintRpt(problems)
+		for _, lp := range problems {
+			if err := rpt.PrintRow(
+				lp.Family, lp.Unit, lp.Check, lp.Problem,
+			); err != nil {
+				reportWriteErr(err)
+			}
+		}
+
+		fmt.Fprintf(prog.stdout, "%d %s found\n",
+			len(problems), english.Plural("problem", len(problems)))
+	}
+}
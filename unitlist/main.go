@@ -4,7 +4,8 @@ package main
 
 import (
 	"fmt"
-	"maps"
+	"io"
+	"math"
 	"os"
 	"slices"
 	"sort"
@@ -13,25 +14,62 @@ import (
 	"github.com/nickwells/col.mod/v6/col"
 	"github.com/nickwells/col.mod/v6/colfmt"
 	"github.com/nickwells/units.mod/v2/units"
+	"github.com/nickwells/unittools/convert"
 )
 
 // Created: Fri Dec 25 18:42:35 2020
 
 type prog struct {
+	stdout     io.Writer
+	exitStatus int
+
 	family *units.Family
 	uName  string
 
 	mustHaveTags    []units.Tag
 	mustNotHaveTags []units.Tag
+	whereStr        string
+	tagFilter       tagExpr
 
 	orderByName bool
+	sortBy      colName
 	showDetail  bool
 	noHeader    bool
+
+	columnSpecs []string
+	columns     []colSpec
+
+	relativeTo string
+	relUnit    units.Unit
+
+	lint    bool
+	summary bool
+
+	format outputFormat
+
+	searchPattern string
+	searchMode    searchMode
+	searchAliases bool
+	searchNotes   bool
+	matcher       func(string) bool
+
+	compareNames []string
+	compareUnits []units.Unit
+
+	minSizeStr string
+	maxSizeStr string
+	minSize    float64
+	maxSize    float64
 }
 
 // newProg returns a new Prog instance with the default values set
 func newProg() *prog {
-	return &prog{}
+	return &prog{
+		stdout:     os.Stdout,
+		format:     fmtText,
+		searchMode: searchSubstring,
+		maxSize:    math.Inf(1),
+	}
 }
 
 func main() {
@@ -40,33 +78,63 @@ func main() {
 
 	ps.Parse()
 
-	if prog.family == nil {
-		prog.listFamilies()
+	prog.run()
+
+	os.Exit(prog.exitStatus)
+}
+
+// run shows the families, the units in a family or the details of a
+// single unit, according to the parameters given
+func (prog *prog) run() {
+	if prog.lint {
+		prog.lintUnits()
+		return
+	}
+
+	if prog.summary {
+		prog.showSummary()
+		return
+	}
+
+	if len(prog.compareUnits) > 0 {
+		prog.showComparison()
+		return
+	}
+
+	if prog.searching() {
+		prog.searchUnits()
 		return
 	}
 
-	if prog.uName == "" {
-		prog.listUnits()
+	if prog.uName != "" {
+		prog.showUnit()
 		return
 	}
 
-	prog.showUnit()
+	if prog.family == nil {
+		prog.listFamilies()
+		return
+	}
+
+	prog.listUnits()
 }
 
-// getUnitIDs gets a sorted list of unit getUnitIDs
-func (prog prog) getUnitIDs() []string {
-	unitIDs := prog.family.GetUnitNames()
+// getUnitIDs gets a sorted list of the IDs of the units in the family. The
+// units are sorted by size or by name and then, if a sort column has been
+// given, by the value in that column.
+func (prog prog) getUnitIDs(f *units.Family) []string {
+	unitIDs := f.GetUnitNames()
 
 	if prog.orderByName {
 		sort.Strings(unitIDs)
 	} else {
 		sort.Slice(unitIDs, func(i, j int) bool {
-			iu, err := prog.family.GetUnit(unitIDs[i])
+			iu, err := f.GetUnit(unitIDs[i])
 			if err != nil {
 				return false
 			}
 
-			ju, err := prog.family.GetUnit(unitIDs[j])
+			ju, err := f.GetUnit(unitIDs[j])
 			if err != nil {
 				return false
 			}
@@ -79,6 +147,23 @@ func (prog prog) getUnitIDs() []string {
 		})
 	}
 
+	if prog.sortBy != "" {
+		us := make([]units.Unit, 0, len(unitIDs))
+
+		for _, uName := range unitIDs {
+			if u, err := f.GetUnit(uName); err == nil {
+				us = append(us, u)
+			}
+		}
+
+		prog.sortUnits(us)
+
+		unitIDs = unitIDs[:0]
+		for _, u := range us {
+			unitIDs = append(unitIDs, u.ID())
+		}
+	}
+
 	return unitIDs
 }
 
@@ -99,94 +184,89 @@ func getUnitTags(u units.Unit) string {
 	return tagStr.String()
 }
 
-// getUnitNotes builds the notes column value for the given unit
-func getUnitNotes(u units.Unit) string {
-	var notes strings.Builder
-	notes.WriteString(u.Notes())
-
-	aliases := u.Aliases()
-	if len(aliases) > 0 {
-		aliasNames := slices.Sorted(maps.Keys(aliases))
+// makeUnitListRpt generates the appropriate report taking into account the
+// noHeader flag and the columns chosen. When searching, the units come from
+// several families and so the report starts with the family name.
+//
+//nolint:mnd
+func (prog prog) makeUnitListRpt() *col.Report {
+	hdr := col.NewHeaderOrPanic()
+	if prog.noHeader {
+		hdr = col.NewHeaderOrPanic(col.HdrOptDontPrint)
+	}
 
-		notes.WriteString("\n\nAliases:")
+	cols := []*col.Col{}
+	if prog.searching() {
+		cols = append(cols, col.New(&colfmt.String{}, "Unit Family"))
+	}
 
-		for _, aName := range aliasNames {
-			notes.WriteString("\n    ")
-			notes.WriteString(aName)
-		}
+	if len(prog.columns) == 0 {
+		cols = append(cols, col.New(&colfmt.String{}, "Unit Name"))
 	}
 
-	if u.ConvPreAdd() != 0 || u.ConvPostAdd() != 0 {
-		notes.WriteString("\n\n" +
-			"The conversion is not a simple multiplication," +
-			" show the unit details for a full explanation.")
+	for _, cs := range prog.columns {
+		cols = append(cols, colDefs[cs.name].makeCol(prog, cs.w))
 	}
 
-	return notes.String()
+	return col.NewReportOrPanic(hdr, prog.stdout, cols[0], cols[1:]...)
 }
 
-// makeUnitListRpt generates the appropriate report taking into account the
-// noHeader and showDetail flags
-//
-//nolint:mnd
-func (prog prog) makeUnitListRpt() *col.Report {
-	hdr := col.NewHeaderOrPanic()
-	if prog.noHeader {
-		hdr = col.NewHeaderOrPanic(col.HdrOptDontPrint)
+// unitIsWanted returns true if the unit is within the size limits and its
+// tags satisfy the tag filter
+func (prog prog) unitIsWanted(u units.Unit) bool {
+	size := convert.Size(units.ValUnit{V: 1, U: u})
+	if size < prog.minSize || size > prog.maxSize {
+		return false
 	}
 
-	if prog.showDetail {
-		return col.NewReportOrPanic(hdr, os.Stdout,
-			col.New(&colfmt.String{}, "Base", "Unit"),
-			col.New(&colfmt.WrappedString{W: 20}, "Unit Name"),
-			col.New(&colfmt.WrappedString{W: 20}, "Tags"),
-			col.New(&colfmt.Float{
-				W:                        20,
-				Prec:                     9,
-				TrimTrailingZeroes:       true,
-				ReformatOutOfBoundValues: true,
-			}, "Conversion", "Factor"),
-			col.New(&colfmt.WrappedString{W: 40}, "Notes"),
-		)
+	return prog.tagFilter == nil || prog.tagFilter.matches(u)
+}
+
+// wantedUnits returns the named units which can be found in the family
+// and which are wanted
+func (prog prog) wantedUnits(f *units.Family, unitIDs []string) []units.Unit {
+	us := []units.Unit{}
+
+	for _, uName := range unitIDs {
+		u, err := f.GetUnit(uName)
+		if err == nil && prog.unitIsWanted(u) {
+			us = append(us, u)
+		}
 	}
 
-	return col.NewReportOrPanic(hdr, os.Stdout,
-		col.New(&colfmt.String{}, "Unit Name"))
+	return us
 }
 
 // printUnitRow prints the row in the unit list report. It returns false if
 // the unit cannot be found, true otherwise.
-func (prog prog) printUnitRow(rpt *col.Report, uName string) bool {
-	u, err := prog.family.GetUnit(uName)
+func (prog prog) printUnitRow(
+	rpt *col.Report, f *units.Family, uName string,
+) bool {
+	u, err := f.GetUnit(uName)
 	if err != nil {
 		return false
 	}
 
-	for _, tag := range prog.mustHaveTags {
-		if !u.HasTag(tag) {
-			return true
-		}
+	if !prog.unitIsWanted(u) {
+		return true
 	}
 
-	if slices.ContainsFunc(prog.mustNotHaveTags, u.HasTag) {
-		return true
+	vals := []any{}
+	if prog.searching() {
+		vals = append(vals, f.Name())
 	}
 
-	if !prog.showDetail {
-		err = rpt.PrintRow(uName)
-	} else {
-		intro := ""
-		if uName == prog.family.BaseUnitName() {
-			intro = ">>>"
-		}
+	if len(prog.columns) == 0 {
+		vals = append(vals, uName)
+	}
 
-		err = rpt.PrintRow(
-			intro, uName, getUnitTags(u), u.ConvFactor(), getUnitNotes(u))
+	for _, cs := range prog.columns {
+		vals = append(vals, colDefs[cs.name].val(prog, u))
 	}
 
-	if err != nil {
+	if err = rpt.PrintRow(vals...); err != nil {
 		fmt.Fprintf(os.Stderr, "Error found while printing the %q units: %v\n",
-			prog.family.Name(), err)
+			f.Name(), err)
 		os.Exit(1)
 	}
 
@@ -195,19 +275,26 @@ func (prog prog) printUnitRow(rpt *col.Report, uName string) bool {
 
 // listUnits reports on the available units in the given family
 func (prog *prog) listUnits() {
-	unitIDs := prog.getUnitIDs()
+	unitIDs := prog.getUnitIDs(prog.family)
+
+	if prog.format != fmtText {
+		prog.writeUnits(prog.wantedUnits(prog.family, unitIDs))
+		return
+	}
+
 	rpt := prog.makeUnitListRpt()
 	badUnits := []string{}
 
 	for _, uName := range unitIDs {
-		if !prog.printUnitRow(rpt, uName) {
+		if !prog.printUnitRow(rpt, prog.family, uName) {
 			badUnits = append(badUnits, uName)
 		}
 	}
 
 	if len(badUnits) != 0 {
-		fmt.Println("These units could not be found in the unit family:")
-		fmt.Println(strings.Join(badUnits, "\n"))
+		fmt.Fprintln(prog.stdout,
+			"These units could not be found in the unit family:")
+		fmt.Fprintln(prog.stdout, strings.Join(badUnits, "\n"))
 	}
 }
 
@@ -238,14 +325,14 @@ func (prog prog) makeFamilyListRpt() *col.Report {
 			maxAliasW = 1
 		}
 
-		return col.NewReportOrPanic(hdr, os.Stdout,
+		return col.NewReportOrPanic(hdr, prog.stdout,
 			col.New(&colfmt.String{W: maxW}, "Unit", "Family"),
 			col.New(&colfmt.WrappedString{W: maxAliasW}, "Aliases"),
 			col.New(&colfmt.String{}, "Description"),
 		)
 	}
 
-	return col.NewReportOrPanic(hdr, os.Stdout,
+	return col.NewReportOrPanic(hdr, prog.stdout,
 		col.New(&colfmt.String{}, "Unit Family"))
 }
 
@@ -275,6 +362,11 @@ func (prog *prog) listFamilies() {
 	validFamilies := units.GetFamilyNames()
 	sort.Strings(validFamilies)
 
+	if prog.format != fmtText {
+		prog.writeFamilies(validFamilies)
+		return
+	}
+
 	rpt := prog.makeFamilyListRpt()
 
 	for _, fName := range validFamilies {
@@ -283,9 +375,46 @@ func (prog *prog) listFamilies() {
 }
 
 // hasTagConstraints returns true if there are any entries in either of the
-// lists of tags to check when constraining the units to show.
+// lists of tags to check or a tag expression constraining the units to
+// show.
 func (prog prog) hasTagConstraints() bool {
-	return len(prog.mustHaveTags) > 0 || len(prog.mustNotHaveTags) > 0
+	return len(prog.mustHaveTags) > 0 || len(prog.mustNotHaveTags) > 0 ||
+		prog.whereStr != ""
+}
+
+// makeTagFilter sets the tag filter from the tag expression and the lists
+// of mandatory and forbidden tags. The tag lists are shorthand for
+// expressions so that a unit must satisfy the expression, have every
+// mandatory tag and have no forbidden tag.
+func (prog *prog) makeTagFilter() error {
+	filters := []tagExpr{}
+
+	if prog.whereStr != "" {
+		e, err := parseTagExpr(prog.whereStr)
+		if err != nil {
+			return err
+		}
+
+		filters = append(filters, e)
+	}
+
+	for _, t := range prog.mustHaveTags {
+		filters = append(filters, tagIs(t))
+	}
+
+	for _, t := range prog.mustNotHaveTags {
+		filters = append(filters, tagNot{e: tagIs(t)})
+	}
+
+	for _, f := range filters {
+		if prog.tagFilter == nil {
+			prog.tagFilter = f
+		} else {
+			prog.tagFilter = tagAnd{l: prog.tagFilter, r: f}
+		}
+	}
+
+	return nil
 }
 
 // checkTagLists returns an error if the same tag appears in both the list of
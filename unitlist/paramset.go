@@ -17,6 +17,39 @@ func makeParamSet(prog *prog) *param.PSet {
 		addParams(prog),
 		addExamples,
 		utparams.AddRefs(pName),
+		utparams.AddCompletionParams(pName,
+			utparams.CompletionArg{
+				ParamName: paramNameFamily,
+				Kind:      utparams.CompleteFamily,
+			},
+			utparams.CompletionArg{
+				ParamName: paramNameUnit,
+				Kind:      utparams.CompleteUnit,
+			},
+			utparams.CompletionArg{
+				ParamName: paramNameTagged,
+				Kind:      utparams.CompleteTag,
+				IsList:    true,
+			},
+			utparams.CompletionArg{
+				ParamName: paramNameNotTagged,
+				Kind:      utparams.CompleteTag,
+				IsList:    true,
+			},
+			utparams.CompletionArg{
+				ParamName: paramNameWhere,
+				Kind:      utparams.CompleteTag,
+			},
+			utparams.CompletionArg{
+				ParamName: paramNameCompare,
+				Kind:      utparams.CompleteUnit,
+				IsList:    true,
+			},
+			utparams.CompletionArg{
+				ParamName: paramNameRelativeTo,
+				Kind:      utparams.CompleteUnit,
+			},
+		),
 
 		utparams.SetProgramDescription(pName),
 	)
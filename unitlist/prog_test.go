@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/nickwells/param.mod/v7/paramset"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+const (
+	testDataDir = "testdata"
+	runSubDir   = "run"
+)
+
+var gfc = testhelper.GoldenFileCfg{
+	DirNames:               []string{testDataDir, runSubDir},
+	Sfx:                    "txt",
+	UpdFlagName:            "upd-gf",
+	KeepBadResultsFlagName: "keep-bad-results",
+}
+
+func init() {
+	gfc.AddUpdateFlag()
+	gfc.AddKeepBadResultsFlag()
+}
+
+func TestRun(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		args          []string
+		expExitStatus int
+	}{
+		{
+			ID:   testhelper.MkID("units-text"),
+			args: []string{"-f", "temperature"},
+		},
+		{
+			ID:   testhelper.MkID("families-csv"),
+			args: []string{"-format", "csv"},
+		},
+		{
+			ID:   testhelper.MkID("units-csv-no-header"),
+			args: []string{"-f", "temperature", "-format", "csv", "-no-hdr"},
+		},
+		{
+			ID: testhelper.MkID("units-markdown-tagged"),
+			args: []string{
+				"-f", "temperature", "-format", "markdown",
+				"-tagged", "metric",
+			},
+		},
+		{
+			ID:   testhelper.MkID("unit-json"),
+			args: []string{"-f", "temperature", "-u", "K", "-format", "json"},
+		},
+		{
+			ID:   testhelper.MkID("unit-text"),
+			args: []string{"-f", "temperature", "-u", "K"},
+		},
+		{
+			ID:   testhelper.MkID("unit-no-family"),
+			args: []string{"-u", "K"},
+		},
+		{
+			ID:   testhelper.MkID("unit-no-family-several"),
+			args: []string{"-u", "m"},
+		},
+		{
+			ID:   testhelper.MkID("unit-no-family-several-json"),
+			args: []string{"-u", "m", "-format", "json"},
+		},
+		{
+			ID: testhelper.MkID("compare"),
+			args: []string{
+				"-f", "volume", "-compare", "gallon,us-gallon,litre",
+			},
+		},
+		{
+			ID:   testhelper.MkID("compare-no-family-csv"),
+			args: []string{"-compare", "C,F,K", "-format", "csv"},
+		},
+		{
+			ID: testhelper.MkID("size-range"),
+			args: []string{
+				"-f", "length", "-min-size", "1m", "-max-size", "1km",
+			},
+		},
+		{
+			ID: testhelper.MkID("size-max-temperature"),
+			args: []string{
+				"-f", "temperature", "-max-size", "1 F", "-by-name",
+			},
+		},
+		{
+			ID: testhelper.MkID("where"),
+			args: []string{
+				"-f", "volume",
+				"-where", "(us customary or imperial) and not drink",
+				"-not-tagged", "historic",
+			},
+		},
+		{
+			ID: testhelper.MkID("columns"),
+			args: []string{
+				"-f", "temperature",
+				"-columns", "base-marker,name,abbreviation:4,formula:24",
+			},
+		},
+		{
+			ID: testhelper.MkID("columns-sort-by"),
+			args: []string{
+				"-f", "length", "-tagged", "SI",
+				"-columns", "name,plural,factor",
+				"-sort-by", "plural",
+			},
+		},
+		{
+			ID: testhelper.MkID("relative-to"),
+			args: []string{
+				"-f", "length", "-tagged", "imperial",
+				"-relative-to", "foot", "-columns", "name,factor,inverse",
+			},
+		},
+		{
+			ID: testhelper.MkID("relative-to-temperature"),
+			args: []string{
+				"-f", "temperature", "-relative-to", "F",
+				"-columns", "name,factor:30,inverse:30",
+			},
+		},
+		{
+			ID:            testhelper.MkID("lint"),
+			args:          []string{"-lint", "-f", "pressure"},
+			expExitStatus: 1,
+		},
+		{
+			ID:            testhelper.MkID("lint-json"),
+			args:          []string{"-lint", "-f", "area", "-format", "json"},
+			expExitStatus: 1,
+		},
+		{
+			ID:   testhelper.MkID("lint-no-problems"),
+			args: []string{"-lint", "-f", "temperature"},
+		},
+		{
+			ID:   testhelper.MkID("summary"),
+			args: []string{"-summary"},
+		},
+		{
+			ID:   testhelper.MkID("summary-family-csv"),
+			args: []string{"-summary", "-f", "temperature", "-format", "csv"},
+		},
+		{
+			ID:   testhelper.MkID("search"),
+			args: []string{"-search", "gallon"},
+		},
+		{
+			ID: testhelper.MkID("search-glob-tagged"),
+			args: []string{
+				"-search", "*gallon", "-search-mode", "glob",
+				"-tagged", "imperial",
+			},
+		},
+		{
+			ID: testhelper.MkID("search-regexp-aliases-json"),
+			args: []string{
+				"-search", "^Celsius$", "-search-mode", "regexp",
+				"-search-aliases", "-format", "json",
+			},
+		},
+		{
+			ID: testhelper.MkID("search-notes-in-family"),
+			args: []string{
+				"-search", "Napoleon", "-search-notes",
+				"-f", "temperature",
+			},
+		},
+		{
+			ID:   testhelper.MkID("search-no-match"),
+			args: []string{"-search", "no-such-unit"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var stdoutBuf bytes.Buffer
+
+			prog := newProg()
+			prog.stdout = &stdoutBuf
+
+			ps := paramset.NewNoHelpNoExitNoErrRpt(addParams(prog))
+			ps.Parse(tc.args)
+
+			if errMap := ps.Errors(); len(errMap) != 0 {
+				t.Log(tc.IDStr())
+				t.Fatalf("\t: unexpected parameter errors: %v", errMap)
+			}
+
+			prog.run()
+
+			testhelper.DiffInt(t, tc.IDStr(), "exit status",
+				prog.exitStatus, tc.expExitStatus)
+			gfc.Check(t, tc.IDStr(), tc.Name, stdoutBuf.Bytes())
+		})
+	}
+}
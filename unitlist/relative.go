@@ -0,0 +1,101 @@
+package main
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/nickwells/units.mod/v2/units"
+	"github.com/nickwells/unittools/convert"
+)
+
+// relFactorPrec is the number of significant figures shown for the
+// conversion factors between a unit and the reference unit
+const relFactorPrec = 9
+
+// relEpsilon is the size of the rounding error below which the scale and
+// offset of a formula are taken to be exactly one and zero
+const relEpsilon = 1e-12
+
+// formatRelFactor formats the conversion factor
+func formatRelFactor(v float64) string {
+	return strconv.FormatFloat(v, 'g', relFactorPrec, 64)
+}
+
+// refUnit returns the unit that the units in the family of the given unit
+// are shown relative to. This is the reference unit, if one has been
+// given, or else the base unit of the family.
+func (prog prog) refUnit(u units.Unit) units.Unit {
+	if prog.relativeTo != "" {
+		return prog.relUnit
+	}
+
+	base, err := u.Family().GetUnit(u.Family().BaseUnitName())
+	if err != nil {
+		return u
+	}
+
+	return base
+}
+
+// refUnitName returns the name of the unit the units are shown relative
+// to, for the column headings
+func (prog prog) refUnitName() string {
+	if prog.relativeTo != "" {
+		return prog.relUnit.ID()
+	}
+
+	return "base unit"
+}
+
+// relConversion describes how to convert a value in the from unit into
+// the to unit. If neither unit has an offset this is the number of to
+// units in one of the from unit. Otherwise it is the formula to apply.
+func relConversion(from, to units.Unit) string {
+	if units.Equals(from, to) {
+		return formatRelFactor(1)
+	}
+
+	if !convert.HasOffset(from) && !convert.HasOffset(to) {
+		v, err := convert.ConvertDelta(units.ValUnit{V: 1, U: from}, to)
+		if err != nil {
+			return err.Error()
+		}
+
+		return formatRelFactor(v.V)
+	}
+
+	v0, err := units.ValUnit{V: 0, U: from}.Convert(to)
+	if err != nil {
+		return err.Error()
+	}
+
+	v1, err := units.ValUnit{V: 1, U: from}.Convert(to)
+	if err != nil {
+		return err.Error()
+	}
+
+	scale, offset := v1.V-v0.V, v0.V
+
+	formula := ""
+	if math.Abs(scale-1) > relEpsilon {
+		formula = "multiply by " + formatRelFactor(scale)
+	}
+
+	if math.Abs(offset) > relEpsilon {
+		if formula != "" {
+			formula += " then "
+		}
+
+		if offset > 0 {
+			formula += "add " + formatRelFactor(offset)
+		} else {
+			formula += "subtract " + formatRelFactor(math.Abs(offset))
+		}
+	}
+
+	if formula == "" {
+		return formatRelFactor(1)
+	}
+
+	return formula
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+	"github.com/nickwells/units.mod/v2/units"
+)
+
+func TestRelConversion(t *testing.T) {
+	length := units.GetFamilyOrPanic(units.Length)
+	temperature := units.GetFamilyOrPanic(units.Temperature)
+
+	testCases := []struct {
+		testhelper.ID
+		f        *units.Family
+		from, to string
+		expVal   string
+	}{
+		{
+			ID: testhelper.MkID("same unit"),
+			f:  temperature, from: "F", to: "F", expVal: "1",
+		},
+		{
+			ID: testhelper.MkID("simple factor"),
+			f:  length, from: "yard", to: "foot", expVal: "3",
+		},
+		{
+			ID: testhelper.MkID("offset only"),
+			f:  temperature, from: "C", to: "K", expVal: "add 273.15",
+		},
+		{
+			ID: testhelper.MkID("scale and offset"),
+			f:  temperature, from: "C", to: "F",
+			expVal: "multiply by 1.8 then add 32",
+		},
+		{
+			ID: testhelper.MkID("scale and negative offset"),
+			f:  temperature, from: "F", to: "C",
+			expVal: "multiply by 0.555555556 then subtract 17.7777778",
+		},
+	}
+
+	for _, tc := range testCases {
+		from := tc.f.GetUnitOrPanic(tc.from)
+		to := tc.f.GetUnitOrPanic(tc.to)
+
+		testhelper.DiffString(t, tc.IDStr(), "conversion",
+			relConversion(from, to), tc.expVal)
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/nickwells/units.mod/v2/units"
+)
+
+// searchMode determines how the search pattern is matched against the unit
+// names
+type searchMode string
+
+const (
+	searchSubstring searchMode = "substring"
+	searchGlob      searchMode = "glob"
+	searchRegexp    searchMode = "regexp"
+)
+
+// searching returns true if the units are to be searched for
+func (prog prog) searching() bool {
+	return prog.searchPattern != ""
+}
+
+// makeMatcher sets the function used to match the search pattern against
+// the unit names. Substring and glob matches ignore case; a regular
+// expression is used as given. It returns an error if the pattern is not
+// valid.
+func (prog *prog) makeMatcher() error {
+	switch prog.searchMode {
+	case searchGlob:
+		pattern := strings.ToLower(prog.searchPattern)
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("bad glob pattern %q: %w",
+				prog.searchPattern, err)
+		}
+
+		prog.matcher = func(s string) bool {
+			matched, _ := path.Match(pattern, strings.ToLower(s))
+			return matched
+		}
+	case searchRegexp:
+		re, err := regexp.Compile(prog.searchPattern)
+		if err != nil {
+			return fmt.Errorf("bad regular expression %q: %w",
+				prog.searchPattern, err)
+		}
+
+		prog.matcher = re.MatchString
+	default:
+		pattern := strings.ToLower(prog.searchPattern)
+		prog.matcher = func(s string) bool {
+			return strings.Contains(strings.ToLower(s), pattern)
+		}
+	}
+
+	return nil
+}
+
+// unitMatches returns true if the search pattern matches any of the names
+// of the unit and, if requested, its aliases or notes.
+func (prog prog) unitMatches(u units.Unit) bool {
+	candidates := []string{u.ID(), u.Name(), u.NamePlural(), u.Abbrev()}
+
+	if prog.searchAliases {
+		for alias := range u.Aliases() {
+			candidates = append(candidates, alias)
+		}
+	}
+
+	if prog.searchNotes {
+		candidates = append(candidates, u.Notes())
+	}
+
+	return slices.ContainsFunc(candidates, prog.matcher)
+}
+
+// searchUnits reports on the units matching the search pattern. All the
+// families are searched unless a family has been given.
+func (prog *prog) searchUnits() {
+	fNames := units.GetFamilyNames()
+	sort.Strings(fNames)
+
+	if prog.family != nil {
+		fNames = []string{prog.family.Name()}
+	}
+
+	matches := []units.Unit{}
+
+	for _, fName := range fNames {
+		f := units.GetFamilyOrPanic(fName)
+
+		for _, u := range prog.wantedUnits(f, prog.getUnitIDs(f)) {
+			if prog.unitMatches(u) {
+				matches = append(matches, u)
+			}
+		}
+	}
+
+	if prog.format != fmtText {
+		prog.writeUnits(matches)
+		return
+	}
+
+	if len(matches) == 0 {
+		fmt.Fprintf(prog.stdout, "no units were found matching %q\n",
+			prog.searchPattern)
+
+		return
+	}
+
+	rpt := prog.makeUnitListRpt()
+	for _, u := range matches {
+		prog.printUnitRow(rpt, u.Family(), u.ID())
+	}
+}
@@ -2,7 +2,10 @@ package main
 
 import (
 	"fmt"
+	"maps"
 	"os"
+	"slices"
+	"sort"
 	"strings"
 
 	"github.com/nickwells/twrap.mod/twrap"
@@ -19,7 +22,8 @@ type unitVal struct {
 	values []prefixedVal
 }
 
-// aliasList returns a list of prefixed values for the aliasList of the unit.
+// aliasList returns a list of prefixed values for the aliasList of the
+// unit, sorted by alias name.
 func aliasList(aliases map[string]string) []prefixedVal {
 	rval := []prefixedVal{}
 	maxAliasNameLen := 0
@@ -28,11 +32,11 @@ func aliasList(aliases map[string]string) []prefixedVal {
 		maxAliasNameLen = max(len(alias), maxAliasNameLen)
 	}
 
-	for alias, notes := range aliases {
+	for _, alias := range slices.Sorted(maps.Keys(aliases)) {
 		rval = append(rval,
 			prefixedVal{
 				pfx: fmt.Sprintf("%*s: ", maxAliasNameLen, alias),
-				val: notes,
+				val: aliases[alias],
 			})
 	}
 
@@ -67,23 +71,79 @@ func unitTags(u units.Unit) string {
 	return tags.String()
 }
 
-// showUnit displays full details of the named Unit
-func (prog prog) showUnit() {
-	u, err := prog.family.GetUnit(prog.uName)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "%q is not a %s\n",
-			prog.uName, prog.family.Description())
+// findUnits returns the units with the given name. If no family has been
+// given then every family is searched and the units are returned in order
+// of family name.
+func (prog prog) findUnits() []units.Unit {
+	if prog.family != nil {
+		u, err := prog.family.GetUnit(prog.uName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%q is not a %s\n",
+				prog.uName, prog.family.Description())
+			os.Exit(1)
+		}
+
+		return []units.Unit{u}
+	}
+
+	fNames := units.GetFamilyNames()
+	sort.Strings(fNames)
+
+	us := []units.Unit{}
+
+	for _, fName := range fNames {
+		u, err := units.GetFamilyOrPanic(fName).GetUnit(prog.uName)
+		if err == nil {
+			us = append(us, u)
+		}
+	}
+
+	if len(us) == 0 {
+		fmt.Fprintf(os.Stderr, "%q is not the name of a unit in any family\n",
+			prog.uName)
 		os.Exit(1)
 	}
 
-	fmt.Printf("%s/%s", prog.family.Name(), prog.uName)
+	return us
+}
+
+// showUnit displays full details of the named Unit. If no family has been
+// given then the details are shown for the unit in each family having a
+// unit with that name.
+func (prog prog) showUnit() {
+	us := prog.findUnits()
+
+	if prog.format != fmtText {
+		if len(us) == 1 {
+			prog.writeUnit(us[0])
+		} else {
+			prog.writeUnits(us)
+		}
+
+		return
+	}
+
+	for i, u := range us {
+		if i > 0 {
+			fmt.Fprintln(prog.stdout)
+		}
+
+		prog.showUnitDetails(u)
+	}
+}
+
+// showUnitDetails displays full details of the Unit
+func (prog prog) showUnitDetails(u units.Unit) {
+	f := u.Family()
+
+	fmt.Fprintf(prog.stdout, "%s/%s", f.Name(), prog.uName)
 
 	unitName := u.ID()
 	if prog.uName != unitName {
-		fmt.Printf(" (= %s)", unitName)
+		fmt.Fprintf(prog.stdout, " (= %s)", unitName)
 	}
 
-	fmt.Println()
+	fmt.Fprintln(prog.stdout)
 
 	uvList := []unitVal{
 		{
@@ -108,7 +168,7 @@ func (prog prog) showUnit() {
 		},
 		{
 			labels: []string{"Base Unit"},
-			values: []prefixedVal{{val: prog.family.BaseUnitName()}},
+			values: []prefixedVal{{val: f.BaseUnitName()}},
 		},
 		{
 			labels: []string{"To convert", "from base units"},
@@ -121,12 +181,12 @@ func (prog prog) showUnit() {
 	}
 
 	maxLabelLen := maxLabelLen(uvList)
-	twc := twrap.NewTWConfOrPanic()
+	twc := twrap.NewTWConfOrPanic(twrap.SetWriter(prog.stdout))
 
 	for _, uv := range uvList {
 		if len(uv.labels) > 1 {
 			for _, l := range uv.labels[:len(uv.labels)-1] {
-				fmt.Printf("%*s\n", maxLabelLen, l)
+				fmt.Fprintf(prog.stdout, "%*s\n", maxLabelLen, l)
 			}
 		}
 
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+
+	"github.com/nickwells/col.mod/v6/col"
+	"github.com/nickwells/col.mod/v6/colfmt"
+	"github.com/nickwells/units.mod/v2/units"
+	"github.com/nickwells/unittools/convert"
+)
+
+// summaryInfo holds the statistics describing a family of units
+type summaryInfo struct {
+	Family      string   `json:"family"`
+	BaseUnit    string   `json:"baseUnit"`
+	UnitCount   int      `json:"unitCount"`
+	AliasCount  int      `json:"aliasCount"`
+	TagsInUse   []string `json:"tagsInUse"`
+	Smallest    string   `json:"smallest"`
+	Largest     string   `json:"largest"`
+	Span        float64  `json:"span"`
+	OffsetUnits int      `json:"offsetUnits"`
+}
+
+// makeSummaryInfo returns the statistics for the named family. The span is
+// the number of orders of magnitude between the sizes of the smallest and
+// largest units.
+func makeSummaryInfo(fName string) summaryInfo {
+	f := units.GetFamilyOrPanic(fName)
+
+	si := summaryInfo{
+		Family:    fName,
+		BaseUnit:  f.BaseUnitName(),
+		TagsInUse: []string{},
+	}
+
+	tags := map[string]bool{}
+
+	var smallest, largest float64
+
+	for _, u := range f.GetUnits() {
+		si.UnitCount++
+		si.AliasCount += len(u.Aliases())
+
+		for _, t := range u.Tags() {
+			tags[string(t)] = true
+		}
+
+		if convert.HasOffset(u) {
+			si.OffsetUnits++
+		}
+
+		size := convert.Size(units.ValUnit{V: 1, U: u})
+
+		if si.Smallest == "" || size < smallest ||
+			(size == smallest && u.ID() < si.Smallest) {
+			smallest, si.Smallest = size, u.ID()
+		}
+
+		if si.Largest == "" || size > largest ||
+			(size == largest && u.ID() < si.Largest) {
+			largest, si.Largest = size, u.ID()
+		}
+	}
+
+	for t := range tags {
+		si.TagsInUse = append(si.TagsInUse, t)
+	}
+
+	slices.Sort(si.TagsInUse)
+
+	if smallest > 0 {
+		si.Span = math.Log10(largest / smallest)
+	}
+
+	return si
+}
+
+// summaryTableHdr returns the column headings for the summary in the CSV
+// and Markdown formats
+func summaryTableHdr() []string {
+	return []string{
+		"Family", "Base Unit", "Units", "Aliases", "Tags In Use",
+		"Smallest", "Largest", "Span", "Offset Units",
+	}
+}
+
+// tableRow returns the summary as a row in the CSV and Markdown formats
+func (si summaryInfo) tableRow() []string {
+	return []string{
+		si.Family,
+		si.BaseUnit,
+		strconv.Itoa(si.UnitCount),
+		strconv.Itoa(si.AliasCount),
+		joinList(si.TagsInUse),
+		si.Smallest,
+		si.Largest,
+		strconv.FormatFloat(si.Span, 'f', 1, 64),
+		strconv.Itoa(si.OffsetUnits),
+	}
+}
+
+// makeSummaryRpt generates the report summarising the families
+//
+//nolint:mnd
+func (prog prog) makeSummaryRpt(infos []summaryInfo) *col.Report {
+	hdr := col.NewHeaderOrPanic()
+	if prog.noHeader {
+		hdr = col.NewHeaderOrPanic(col.HdrOptDontPrint)
+	}
+
+	familyW, baseW, smallW, largeW := 0, 0, 0, 0
+	for _, si := range infos {
+		familyW = max(len(si.Family), familyW)
+		baseW = max(len(si.BaseUnit), baseW)
+		smallW = max(len(si.Smallest), smallW)
+		largeW = max(len(si.Largest), largeW)
+	}
+
+	return col.NewReportOrPanic(hdr, prog.stdout,
+		col.New(&colfmt.String{W: familyW}, "Unit", "Family"),
+		col.New(&colfmt.String{W: baseW}, "Base", "Unit"),
+		col.New(&colfmt.Int{W: 5}, "Units"),
+		col.New(&colfmt.Int{W: 7}, "Aliases"),
+		col.New(&colfmt.Int{W: 6}, "Tags", "In Use"),
+		col.New(&colfmt.String{W: smallW}, "Smallest", "Unit"),
+		col.New(&colfmt.String{W: largeW}, "Largest", "Unit"),
+		col.New(&colfmt.Float{W: 5, Prec: 1}, "Span"),
+		col.New(&colfmt.Int{W: 6}, "Offset", "Units"),
+	)
+}
+
+// showSummary reports the statistics for every family, or just for the
+// given family
+func (prog *prog) showSummary() {
+	fNames := units.GetFamilyNames()
+	sort.Strings(fNames)
+
+	if prog.family != nil {
+		fNames = []string{prog.family.Name()}
+	}
+
+	infos := make([]summaryInfo, 0, len(fNames))
+	for _, fName := range fNames {
+		infos = append(infos, makeSummaryInfo(fName))
+	}
+
+	switch prog.format {
+	case fmtJSON:
+		prog.writeJSON(infos)
+	case fmtCSV, fmtMarkdown:
+		rows := make([][]string, 0, len(infos))
+		for _, si := range infos {
+			rows = append(rows, si.tableRow())
+		}
+
+		prog.writeTable(summaryTableHdr(), rows)
+	default:
+		rpt := prog.makeSummaryRpt(infos)
+		for _, si := range infos {
+			if err := rpt.PrintRow(
+				si.Family, si.BaseUnit,
+				si.UnitCount, si.AliasCount, len(si.TagsInUse),
+				si.Smallest, si.Largest, si.Span, si.OffsetUnits,
+			); err != nil {
+				reportWriteErr(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/nickwells/english.mod/english"
+	"github.com/nickwells/units.mod/v2/units"
+)
+
+// tagExpr is a boolean expression over the tags of a unit
+type tagExpr interface {
+	// matches returns true if the unit satisfies the expression
+	matches(u units.Unit) bool
+	// String returns the expression in the form in which it was parsed
+	String() string
+}
+
+// tagIs is satisfied if the unit has the tag
+type tagIs units.Tag
+
+func (t tagIs) matches(u units.Unit) bool { return u.HasTag(units.Tag(t)) }
+func (t tagIs) String() string            { return string(t) }
+
+// tagNot is satisfied if the expression is not
+type tagNot struct{ e tagExpr }
+
+func (t tagNot) matches(u units.Unit) bool { return !t.e.matches(u) }
+func (t tagNot) String() string            { return "not " + t.e.String() }
+
+// tagAnd is satisfied if both expressions are
+type tagAnd struct{ l, r tagExpr }
+
+func (t tagAnd) matches(u units.Unit) bool {
+	return t.l.matches(u) && t.r.matches(u)
+}
+
+func (t tagAnd) String() string {
+	return "(" + t.l.String() + " and " + t.r.String() + ")"
+}
+
+// tagOr is satisfied if either expression is
+type tagOr struct{ l, r tagExpr }
+
+func (t tagOr) matches(u units.Unit) bool {
+	return t.l.matches(u) || t.r.matches(u)
+}
+
+func (t tagOr) String() string {
+	return "(" + t.l.String() + " or " + t.r.String() + ")"
+}
+
+// These are the keywords and punctuation of a tag expression
+const (
+	tokAnd    = "and"
+	tokOr     = "or"
+	tokNot    = "not"
+	tokLParen = "("
+	tokRParen = ")"
+)
+
+// tagExprParser parses a tag expression. The grammar is:
+//
+//	expr   = term { "or" term }
+//	term   = factor { "and" factor }
+//	factor = "not" factor | "(" expr ")" | tag
+//
+// The keywords are case-insensitive and "&", "|" and "!" may be used in
+// place of "and", "or" and "not". A tag name is one or more words which
+// are not keywords, so tags containing spaces need not be quoted. Tag
+// names are matched against the valid tags ignoring case.
+type tagExprParser struct {
+	src    string
+	tokens []string
+	pos    int
+	tags   map[string]units.Tag
+}
+
+// tokenize splits the expression into keywords, parentheses and tag names
+func tokenize(s string) []string {
+	s = strings.NewReplacer(
+		"(", " ( ", ")", " ) ",
+		"&", " and ", "|", " or ", "!", " not ",
+	).Replace(s)
+
+	tokens := []string{}
+	tagWords := []string{}
+
+	endTag := func() {
+		if len(tagWords) > 0 {
+			tokens = append(tokens, strings.Join(tagWords, " "))
+			tagWords = tagWords[:0]
+		}
+	}
+
+	for _, word := range strings.Fields(s) {
+		switch lc := strings.ToLower(word); lc {
+		case tokAnd, tokOr, tokNot, tokLParen, tokRParen:
+			endTag()
+
+			tokens = append(tokens, lc)
+		default:
+			tagWords = append(tagWords, word)
+		}
+	}
+
+	endTag()
+
+	return tokens
+}
+
+// parseTagExpr parses the string as a tag expression. It returns an error
+// if the expression is malformed or refers to an unknown tag.
+func parseTagExpr(s string) (tagExpr, error) {
+	p := &tagExprParser{
+		src:    s,
+		tokens: tokenize(s),
+		tags:   map[string]units.Tag{},
+	}
+
+	for _, tn := range units.GetTagNames() {
+		p.tags[strings.ToLower(tn)] = units.Tag(tn)
+	}
+
+	e, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok, ok := p.peek(); ok {
+		return nil, p.errorf("unexpected %q", tok)
+	}
+
+	return e, nil
+}
+
+// errorf returns an error describing a problem with the expression
+func (p *tagExprParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("bad tag expression %q: %s",
+		p.src, fmt.Sprintf(format, args...))
+}
+
+// peek returns the next token, if any, without consuming it
+func (p *tagExprParser) peek() (string, bool) {
+	if p.pos >= len(p.tokens) {
+		return "", false
+	}
+
+	return p.tokens[p.pos], true
+}
+
+// accept consumes the next token if it is the given token
+func (p *tagExprParser) accept(tok string) bool {
+	if next, ok := p.peek(); ok && next == tok {
+		p.pos++
+		return true
+	}
+
+	return false
+}
+
+// expr parses a sequence of terms separated by "or"
+func (p *tagExprParser) expr() (tagExpr, error) {
+	e, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.accept(tokOr) {
+		r, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+
+		e = tagOr{l: e, r: r}
+	}
+
+	return e, nil
+}
+
+// term parses a sequence of factors separated by "and"
+func (p *tagExprParser) term() (tagExpr, error) {
+	e, err := p.factor()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.accept(tokAnd) {
+		r, err := p.factor()
+		if err != nil {
+			return nil, err
+		}
+
+		e = tagAnd{l: e, r: r}
+	}
+
+	return e, nil
+}
+
+// factor parses a negated factor, a parenthesised expression or a tag
+func (p *tagExprParser) factor() (tagExpr, error) {
+	tok, ok := p.peek()
+	if !ok {
+		return nil, p.errorf("unexpected end of expression")
+	}
+
+	switch tok {
+	case tokNot:
+		p.pos++
+
+		e, err := p.factor()
+		if err != nil {
+			return nil, err
+		}
+
+		return tagNot{e: e}, nil
+	case tokLParen:
+		p.pos++
+
+		e, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+
+		if !p.accept(tokRParen) {
+			return nil, p.errorf("missing %q", tokRParen)
+		}
+
+		return e, nil
+	case tokAnd, tokOr, tokRParen:
+		return nil, p.errorf("unexpected %q", tok)
+	}
+
+	p.pos++
+
+	t, ok := p.tags[strings.ToLower(tok)]
+	if !ok {
+		return nil, p.errorf("unknown tag %q, the valid tags are: %s",
+			tok, english.JoinQuoted(slices.Sorted(slices.Values(
+				units.GetTagNames())), ", ", " and "))
+	}
+
+	return tagIs(t), nil
+}
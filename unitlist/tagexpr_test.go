@@ -0,0 +1,68 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestParseTagExpr(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		s      string
+		expStr string
+	}{
+		{
+			ID: testhelper.MkID("single tag"),
+			s:  "metric", expStr: "metric",
+		},
+		{
+			ID: testhelper.MkID("tag with spaces, any case"),
+			s:  "us CUSTOMARY", expStr: "US customary",
+		},
+		{
+			ID:     testhelper.MkID("precedence"),
+			s:      "SI or metric and not historic",
+			expStr: "(SI or (metric and not historic))",
+		},
+		{
+			ID:     testhelper.MkID("parentheses"),
+			s:      "(si | metric) & !historic",
+			expStr: "((SI or metric) and not historic)",
+		},
+		{
+			ID:     testhelper.MkID("unknown tag"),
+			ExpErr: testhelper.MkExpErr(`unknown tag "metrc"`),
+			s:      "metrc",
+		},
+		{
+			ID:     testhelper.MkID("missing close paren"),
+			ExpErr: testhelper.MkExpErr(`missing ")"`),
+			s:      "(metric or SI",
+		},
+		{
+			ID:     testhelper.MkID("trailing operator"),
+			ExpErr: testhelper.MkExpErr("unexpected end of expression"),
+			s:      "metric and",
+		},
+		{
+			ID:     testhelper.MkID("leading operator"),
+			ExpErr: testhelper.MkExpErr(`unexpected "or"`),
+			s:      "or metric",
+		},
+		{
+			ID:     testhelper.MkID("unbalanced close paren"),
+			ExpErr: testhelper.MkExpErr(`unexpected ")"`),
+			s:      "metric)",
+		},
+	}
+
+	for _, tc := range testCases {
+		e, err := parseTagExpr(tc.s)
+		if testhelper.CheckExpErr(t, err, tc) && err == nil {
+			testhelper.DiffString(t, tc.IDStr(), "expression",
+				e.String(), tc.expStr)
+		}
+	}
+}
@@ -96,15 +96,20 @@ func (prog prog) showTagDetails() {
 	twc.WrapPrefixed("Notes: ", prog.tag.Notes(), 0)
 }
 
+const (
+	paramNameLong = "long"
+	paramNameTag  = "tag"
+)
+
 // addParams will add parameters to the passed ParamSet
 func addParams(prog *prog) param.PSetOptFunc {
 	return func(ps *param.PSet) error {
-		ps.Add("long", psetter.Bool{Value: &prog.showDetails},
+		ps.Add(paramNameLong, psetter.Bool{Value: &prog.showDetails},
 			"show the full details when displaying the tag",
 			param.AltNames("l"),
 		)
 
-		ps.Add("tag", unitsetter.TagSetter{Value: &prog.tag},
+		ps.Add(paramNameTag, unitsetter.TagSetter{Value: &prog.tag},
 			"show the full details of just this tag",
 			param.AltNames("t"),
 		)
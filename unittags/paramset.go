@@ -16,6 +16,12 @@ func makeParamSet(prog *prog) *param.PSet {
 
 		addParams(prog),
 		utparams.AddRefs(pName),
+		utparams.AddCompletionParams(pName,
+			utparams.CompletionArg{
+				ParamName: paramNameTag,
+				Kind:      utparams.CompleteTag,
+			},
+		),
 
 		utparams.SetProgramDescription(pName),
 	)